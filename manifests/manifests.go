@@ -0,0 +1,9 @@
+// Package manifests exposes the operator's release manifests so that they can be rendered from the binary.
+package manifests
+
+import "embed"
+
+// FS holds the release manifests and the image-references used to substitute their pull specs.
+//
+//go:embed *.yaml image-references
+var FS embed.FS
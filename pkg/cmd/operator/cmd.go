@@ -16,6 +16,7 @@ func NewOperator() *cobra.Command {
 
 	cmd := controllercmd.
 		NewControllerCommandConfig("config-operator", version.Get(), o.RunOperator, clock.RealClock{}).
+		WithHealthChecks(o.HealthChecks()...).
 		NewCommand()
 	cmd.Use = "operator"
 	cmd.Short = "Start the Cluster Config Operator"
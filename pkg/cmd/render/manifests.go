@@ -0,0 +1,123 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// manifestPayloadVersion is the placeholder version in the release manifests, replaced by the payload version.
+	manifestPayloadVersion = "0.0.1-snapshot"
+
+	imageReferencesFile     = "image-references"
+	clusterProfilePrefix    = "include.release.openshift.io/"
+	deleteAnnotation        = "release.openshift.io/delete"
+	defaultClusterProfile   = "self-managed-high-availability"
+	operatorImageReference  = "cluster-config-operator"
+	configAPIImageReference = "cluster-config-api"
+)
+
+// manifestTemplateData holds the values substituted into the release manifests.
+type manifestTemplateData struct {
+	// Images maps image-references tag names to the pull spec to use. Tags without a value are left untouched.
+	Images map[string]string
+	// PayloadVersion replaces the placeholder version of the release manifests.
+	PayloadVersion string
+	// ClusterProfile selects the manifests included for a given include.release.openshift.io/<profile> annotation.
+	ClusterProfile string
+}
+
+// renderOperatorManifests renders the release manifests in manifestFS, like the cluster-version-operator would,
+// and writes the ones included in the cluster profile to outputDir.
+func renderOperatorManifests(manifestFS fs.FS, data manifestTemplateData, outputDir string) error {
+	replacements, err := imageReplacements(manifestFS, data.Images)
+	if err != nil {
+		return err
+	}
+	if len(data.PayloadVersion) > 0 {
+		replacements = append(replacements, manifestPayloadVersion, data.PayloadVersion)
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	files, err := fs.Glob(manifestFS, "*.yaml")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %v: %w", outputDir, err)
+	}
+	for _, file := range files {
+		content, err := fs.ReadFile(manifestFS, file)
+		if err != nil {
+			return err
+		}
+		include, err := includedInClusterProfile(content, data.ClusterProfile)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", file, err)
+		}
+		if !include {
+			continue
+		}
+		rendered := replacer.Replace(string(content))
+		if err := os.WriteFile(filepath.Join(outputDir, file), []byte(rendered), 0644); err != nil {
+			return fmt.Errorf("failed to write %q: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// imageReplacements returns old, new pairs for every image-references tag with a pull spec in images.
+func imageReplacements(manifestFS fs.FS, images map[string]string) ([]string, error) {
+	content, err := fs.ReadFile(manifestFS, imageReferencesFile)
+	if err != nil {
+		return nil, err
+	}
+	imageStream := &imagev1.ImageStream{}
+	if err := yaml.Unmarshal(content, imageStream); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", imageReferencesFile, err)
+	}
+
+	var ret []string
+	for _, tag := range imageStream.Spec.Tags {
+		if tag.From == nil || len(images[tag.Name]) == 0 {
+			continue
+		}
+		ret = append(ret, tag.From.Name, images[tag.Name])
+	}
+	return ret, nil
+}
+
+// includedInClusterProfile follows the cluster-version-operator rules: manifests without any cluster profile
+// annotation are included everywhere, the others only for the profiles they opt into. Manifests annotated with
+// release.openshift.io/delete are deleted rather than applied, so they are never included.
+func includedInClusterProfile(content []byte, profile string) (bool, error) {
+	obj := &metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(bytes.TrimSpace(content), obj); err != nil {
+		return false, err
+	}
+	if obj.Annotations[deleteAnnotation] == "true" {
+		return false, nil
+	}
+	hasProfile := false
+	for k := range obj.Annotations {
+		if strings.HasPrefix(k, clusterProfilePrefix) {
+			hasProfile = true
+			break
+		}
+	}
+	if !hasProfile {
+		return true, nil
+	}
+	return obj.Annotations[clusterProfilePrefix+profile] == "true", nil
+}
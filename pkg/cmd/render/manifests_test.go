@@ -0,0 +1,116 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cluster-config-operator/manifests"
+)
+
+func Test_renderOperatorManifests(t *testing.T) {
+	manifestFS := fstest.MapFS{
+		"image-references": {Data: []byte(`kind: ImageStream
+apiVersion: image.openshift.io/v1
+spec:
+  tags:
+  - name: cluster-config-operator
+    from:
+      kind: DockerImage
+      name: quay.io/openshift/origin-cluster-config-operator:v4.0
+  - name: cluster-config-api
+    from:
+      kind: DockerImage
+      name: quay.io/openshift/origin-cluster-config-api:v4.0
+`)},
+		"00_deployment.yaml": {Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+spec:
+  template:
+    spec:
+      initContainers:
+      - image: quay.io/openshift/origin-cluster-config-api:v4.0
+      containers:
+      - image: quay.io/openshift/origin-cluster-config-operator:v4.0
+        env:
+        - name: OPERATOR_IMAGE_VERSION
+          value: "0.0.1-snapshot"
+`)},
+		"01_hypershift.yaml": {Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hypershift-only
+  annotations:
+    include.release.openshift.io/hypershift: "true"
+`)},
+		"02_everywhere.yaml": {Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: everywhere
+`)},
+		"03_deleted.yaml": {Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: deleted
+  annotations:
+    include.release.openshift.io/self-managed-high-availability: "true"
+    release.openshift.io/delete: "true"
+`)},
+	}
+
+	outputDir := t.TempDir()
+	err := renderOperatorManifests(manifestFS, manifestTemplateData{
+		Images: map[string]string{
+			operatorImageReference: "example.com/config-operator@sha256:1234",
+		},
+		PayloadVersion: "4.99.0",
+		ClusterProfile: defaultClusterProfile,
+	}, outputDir)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"00_deployment.yaml", "02_everywhere.yaml"}, names)
+
+	deployment, err := os.ReadFile(filepath.Join(outputDir, "00_deployment.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(deployment), "- image: example.com/config-operator@sha256:1234")
+	assert.Contains(t, string(deployment), "- image: quay.io/openshift/origin-cluster-config-api:v4.0")
+	assert.Contains(t, string(deployment), `value: "4.99.0"`)
+	assert.NotContains(t, string(deployment), "0.0.1-snapshot")
+}
+
+func Test_renderOperatorManifests_release(t *testing.T) {
+	outputDir := t.TempDir()
+	err := renderOperatorManifests(manifests.FS, manifestTemplateData{
+		Images: map[string]string{
+			operatorImageReference:  "example.com/config-operator:test",
+			configAPIImageReference: "example.com/config-api:test",
+		},
+		PayloadVersion: "4.99.0",
+		ClusterProfile: defaultClusterProfile,
+	}, outputDir)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.NotEmpty(t, entries)
+	for _, e := range entries {
+		content, err := os.ReadFile(filepath.Join(outputDir, e.Name()))
+		require.NoError(t, err)
+		assert.Falsef(t, strings.Contains(string(content), "quay.io/openshift/"), "%s still references a release image", e.Name())
+	}
+}
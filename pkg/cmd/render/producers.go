@@ -0,0 +1,128 @@
+package render
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/aws_platform_service_location"
+	"github.com/openshift/cluster-config-operator/pkg/operator/infrastructure_normalizer"
+	kubecloudconfig "github.com/openshift/cluster-config-operator/pkg/operator/kube_cloud_config"
+	"github.com/openshift/cluster-config-operator/pkg/util"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+)
+
+// ProducerInput holds the inputs available at bootstrap to render the output of in-cluster controllers.
+type ProducerInput struct {
+	// Infrastructure is the cluster Infrastructure provided by the installer.
+	Infrastructure *configv1.Infrastructure
+	// CloudProviderConfig is the user provided openshift-config cloud provider ConfigMap, or nil when not provided.
+	CloudProviderConfig *corev1.ConfigMap
+	// FeatureGates are the feature gates from the rendered FeatureGate manifest, or nil when not provided.
+	FeatureGates featuregates.FeatureGateAccess
+}
+
+// Producer renders the bootstrap-time equivalent of an in-cluster controller.
+// The returned objects are written into the manifests directory so that they exist before the controller runs.
+type Producer func(input *ProducerInput) ([]runtime.Object, error)
+
+var producers = map[string]Producer{}
+
+// RegisterProducer adds the producer for the named controller to the set run by render.
+// It panics if a producer is already registered under name.
+func RegisterProducer(name string, producer Producer) {
+	if _, ok := producers[name]; ok {
+		panic(fmt.Sprintf("render producer %q registered twice", name))
+	}
+	producers[name] = producer
+}
+
+func init() {
+	RegisterProducer("infrastructure-status", infrastructureStatusProducer)
+	RegisterProducer("kube-cloud-config", kubeCloudConfigProducer)
+}
+
+// infrastructureStatusProducer renders the Infrastructure with the status maintained by the
+// InfrastructureNormalizerController and the AWSPlatformServiceLocationController.
+func infrastructureStatusProducer(input *ProducerInput) ([]runtime.Object, error) {
+	infra := input.Infrastructure.DeepCopy()
+	if _, err := infrastructure_normalizer.Normalize(infra); err != nil {
+		return nil, err
+	}
+	if util.PlatformType(infra) == configv1.AWSPlatformType {
+		services, err := aws_platform_service_location.ServiceEndpoints(infra)
+		if err != nil {
+			return nil, err
+		}
+		// endpoints the installer wrote to the status are kept when the spec has none
+		if len(services) > 0 {
+			if infra.Status.PlatformStatus.AWS == nil {
+				infra.Status.PlatformStatus.AWS = &configv1.AWSPlatformStatus{}
+			}
+			infra.Status.PlatformStatus.AWS.ServiceEndpoints = services
+		}
+	}
+	infra.SetGroupVersionKind(configv1.GroupVersion.WithKind("Infrastructure"))
+	return []runtime.Object{infra}, nil
+}
+
+// kubeCloudConfigProducer renders the openshift-config-managed/kube-cloud-config maintained by the KubeCloudConfigController.
+func kubeCloudConfigProducer(input *ProducerInput) ([]runtime.Object, error) {
+	target, err := kubecloudconfig.BootstrapConfigMap(input.Infrastructure, input.CloudProviderConfig, input.FeatureGates)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, nil
+	}
+	return []runtime.Object{target}, nil
+}
+
+// runProducers runs every registered producer in name order and writes their output into manifestDir.
+func runProducers(input *ProducerInput, manifestDir string) error {
+	names := make([]string, 0, len(producers))
+	for name := range producers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		objs, err := producers[name](input)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		for _, obj := range objs {
+			if err := writeManifest(manifestDir, obj); err != nil {
+				return fmt.Errorf("failed to write %s output: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeManifest writes obj to manifestDir using a file name derived from its kind, namespace and name.
+func writeManifest(manifestDir string, obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	nameParts := []string{"0000_10_config-operator"}
+	if ns := accessor.GetNamespace(); len(ns) > 0 {
+		nameParts = append(nameParts, ns)
+	}
+	nameParts = append(nameParts, accessor.GetName(), strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind))
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(manifestDir, strings.Join(nameParts, "_")+".yaml"), data, 0644)
+}
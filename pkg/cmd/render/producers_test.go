@@ -0,0 +1,132 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func Test_runProducers(t *testing.T) {
+	cases := []struct {
+		name  string
+		input *ProducerInput
+
+		files map[string]runtime.Object
+	}{{
+		name: "none platform without cloud config",
+		input: &ProducerInput{
+			Infrastructure: &configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.NonePlatformType}}},
+		},
+		files: map[string]runtime.Object{
+			"0000_10_config-operator_cluster_infrastructure.yaml": &configv1.Infrastructure{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "Infrastructure"},
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{Platform: configv1.NonePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.NonePlatformType}},
+			},
+		},
+	}, {
+		name: "gcp platform with cloud config",
+		input: &ProducerInput{
+			Infrastructure: &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}},
+				Status:     configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType}},
+			},
+			CloudProviderConfig: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"},
+				Data:       map[string]string{"config": "[global]\n"},
+			},
+		},
+		files: map[string]runtime.Object{
+			"0000_10_config-operator_cluster_infrastructure.yaml": &configv1.Infrastructure{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "Infrastructure"},
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}},
+				Status:     configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType}},
+			},
+			"0000_10_config-operator_openshift-config-managed_kube-cloud-config_configmap.yaml": &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed", Annotations: map[string]string{
+					"kube-cloud-config.config.openshift.io/content-hash": "3a612e2fedc28762e8eb7ab7e06d849bbaa6266266b78da190ff0515d01ef289",
+				}},
+				Data: map[string]string{"cloud.conf": "[global]\n"},
+			},
+		},
+	}, {
+		name: "aws platform with service endpoints",
+		input: &ProducerInput{
+			Infrastructure: &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "test-region", ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "https://ec2.local"}}}}},
+			},
+		},
+		files: map[string]runtime.Object{
+			"0000_10_config-operator_cluster_infrastructure.yaml": &configv1.Infrastructure{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "config.openshift.io/v1", Kind: "Infrastructure"},
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "test-region", ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "https://ec2.local"}}}}},
+			},
+			"0000_10_config-operator_openshift-config-managed_kube-cloud-config_configmap.yaml": &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed", Annotations: map[string]string{
+					"kube-cloud-config.config.openshift.io/content-hash": "e26dc35bdf603b6b363747cc5fcb6d6fb2466d725d89bd16abc5a2689023fe6c",
+				}},
+				Data: map[string]string{"cloud.conf": `
+[ServiceOverride "0"]
+	Service = ec2
+	Region = test-region
+	URL = https://ec2.local
+	SigningRegion = test-region
+`},
+			},
+		},
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			manifestDir := t.TempDir()
+			require.NoError(t, runProducers(test.input, manifestDir))
+
+			entries, err := os.ReadDir(manifestDir)
+			require.NoError(t, err)
+			got := map[string]runtime.Object{}
+			for _, e := range entries {
+				content, err := os.ReadFile(filepath.Join(manifestDir, e.Name()))
+				require.NoError(t, err)
+				var obj runtime.Object = &corev1.ConfigMap{}
+				if strings.HasSuffix(e.Name(), "_infrastructure.yaml") {
+					obj = &configv1.Infrastructure{}
+				}
+				require.NoError(t, yaml.Unmarshal(content, obj))
+				got[e.Name()] = obj
+			}
+			assert.Equal(t, test.files, got)
+		})
+	}
+}
+
+func TestRegisterProducer_duplicate(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterProducer("kube-cloud-config", kubeCloudConfigProducer)
+	})
+}
+
+func Test_infrastructureStatusProducer_invalid(t *testing.T) {
+	_, err := infrastructureStatusProducer(&ProducerInput{Infrastructure: &configv1.Infrastructure{
+		Spec: configv1.InfrastructureSpec{PlatformSpec: configv1.PlatformSpec{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformSpec{
+			ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "http://ec2.local"}},
+		}}},
+		Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+	}})
+	assert.EqualError(t, err, `spec.platformSpec.aws.serviceEndpoints[0].url: Invalid value: "http://ec2.local": invalid scheme http, only https allowed`)
+}
@@ -8,10 +8,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-config-operator/manifests"
 	kubecloudconfig "github.com/openshift/cluster-config-operator/pkg/operator/kube_cloud_config"
+	"github.com/openshift/cluster-config-operator/pkg/util"
 	genericrenderoptions "github.com/openshift/library-go/pkg/operator/render/options"
 )
 
@@ -27,6 +30,12 @@ type renderOpts struct {
 	clusterInfrastructureInputFile string
 	cloudProviderConfigInputFile   string
 	cloudProviderConfigOutputFile  string
+
+	renderControllerManifests bool
+
+	operatorManifestsOutputDir string
+	configAPIImage             string
+	clusterProfile             string
 }
 
 // NewRenderCommand creates a render command.
@@ -36,6 +45,7 @@ func NewRenderCommand() *cobra.Command {
 		manifest: *genericrenderoptions.NewManifestOptions("config", "openshift/origin-cluster-config-operator:latest"),
 	}
 	renderOpts.generic.PayloadVersion = "0.0.1-snapshot"
+	renderOpts.clusterProfile = defaultClusterProfile
 
 	cmd := &cobra.Command{
 		Use:   "render",
@@ -76,6 +86,13 @@ func (r *renderOpts) AddFlags(fs *pflag.FlagSet) {
 	// This is the generated kube cloud config
 	fs.StringVar(&r.cloudProviderConfigOutputFile, "cloud-provider-config-output-file", r.cloudProviderConfigOutputFile, "Output path for the generated cloud provider config file.")
 
+	// These are the bootstrap equivalents of the objects maintained by the in-cluster controllers, see RegisterProducer
+	fs.BoolVar(&r.renderControllerManifests, "render-controller-manifests", r.renderControllerManifests, "Render the objects maintained by the operator's controllers, like openshift-config-managed/kube-cloud-config and the Infrastructure status, into the manifests directory. Requires cluster-infrastructure-input-file.")
+
+	// These render the operator's own release manifests, as the cluster-version-operator would apply them
+	fs.StringVar(&r.operatorManifestsOutputDir, "operator-manifests-output-dir", r.operatorManifestsOutputDir, "Output path for the rendered config-operator manifests. Manifests are not rendered if empty.")
+	fs.StringVar(&r.configAPIImage, "config-api-image", r.configAPIImage, "Image to use for the cluster-config-api init container in the rendered config-operator manifests.")
+	fs.StringVar(&r.clusterProfile, "cluster-profile", r.clusterProfile, "Cluster profile used to select the rendered config-operator manifests.")
 }
 
 // Validate verifies the inputs.
@@ -99,6 +116,14 @@ func (r *renderOpts) Validate() error {
 		}
 	}
 
+	if r.renderControllerManifests && len(r.clusterInfrastructureInputFile) == 0 {
+		return fmt.Errorf("cluster-infrastructure-input-file must be specified when rendering controller manifests")
+	}
+
+	if len(r.operatorManifestsOutputDir) > 0 && len(r.clusterProfile) == 0 {
+		return fmt.Errorf("cluster-profile must be specified when rendering config-operator manifests")
+	}
+
 	return nil
 }
 
@@ -163,9 +188,59 @@ func (r *renderOpts) Run() error {
 		}
 	}
 
+	if r.renderControllerManifests {
+		input, err := r.producerInput()
+		if err != nil {
+			return err
+		}
+		if err := runProducers(input, filepath.Join(r.generic.AssetOutputDir, "manifests")); err != nil {
+			return err
+		}
+	}
+
+	if len(r.operatorManifestsOutputDir) > 0 {
+		data := manifestTemplateData{
+			Images: map[string]string{
+				operatorImageReference:  renderConfig.Image,
+				configAPIImageReference: r.configAPIImage,
+			},
+			PayloadVersion: r.generic.PayloadVersion,
+			ClusterProfile: r.clusterProfile,
+		}
+		if err := renderOperatorManifests(manifests.FS, data, r.operatorManifestsOutputDir); err != nil {
+			return fmt.Errorf("failed to render config-operator manifests: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// producerInput reads the inputs for the render producers.
+func (r *renderOpts) producerInput() (*ProducerInput, error) {
+	input := &ProducerInput{Infrastructure: &configv1.Infrastructure{}}
+	if err := util.ReadManifestFile(r.clusterInfrastructureInputFile, configv1.GroupVersion.WithKind("Infrastructure"), input.Infrastructure); err != nil {
+		return nil, fmt.Errorf("failed to read infrastructure: %w", err)
+	}
+	if len(r.cloudProviderConfigInputFile) > 0 {
+		input.CloudProviderConfig = &corev1.ConfigMap{}
+		if err := util.ReadManifestFile(r.cloudProviderConfigInputFile, corev1.SchemeGroupVersion.WithKind("ConfigMap"), input.CloudProviderConfig); err != nil {
+			return nil, fmt.Errorf("failed to read cloud provider config: %w", err)
+		}
+	}
+	renderedManifests, err := r.generic.ReadInputManifests()
+	if err != nil {
+		return nil, fmt.Errorf("error reading input manifests: %w", err)
+	}
+	if len(renderedManifests.ListManifestOfType(configv1.GroupVersion.WithKind("FeatureGate"))) > 0 {
+		featureGates, err := r.generic.FeatureGates()
+		if err != nil {
+			return nil, err
+		}
+		input.FeatureGates = featureGates
+	}
+	return input, nil
+}
+
 func featureGateManifests(o genericrenderoptions.GenericOptions) (genericrenderoptions.RenderedManifests, error) {
 	if len(o.RenderedManifestInputFilenames) == 0 {
 		return nil, fmt.Errorf("cannot return FeatureGate without rendered manifests")
@@ -2,6 +2,7 @@ package aws_platform_service_location
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -11,19 +12,27 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/cache"
 
 	configv1 "github.com/openshift/api/config/v1"
+	applyconfigv1 "github.com/openshift/client-go/config/applyconfigurations/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
+	"github.com/openshift/cluster-config-operator/pkg/operator/infrastructurestatus"
+	"github.com/openshift/cluster-config-operator/pkg/util"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 )
 
+const fieldManager = "AWSPlatformServiceLocationController"
+
 // AWSPlatformServiceLocationController is responsible for syncing and validating the service endpoints for AWS APIs
 // provided by the user using the infrastructure.config.openshift.io/cluster object.
+// It owns status.platformStatus.aws.serviceEndpoints and writes them with server-side apply.
 type AWSPlatformServiceLocationController struct {
 	infraClient configv1client.InfrastructureInterface
 	infraLister configlistersv1.InfrastructureLister
@@ -42,7 +51,7 @@ func NewController(operatorClient operatorv1helpers.OperatorClient,
 			operatorClient.Informer(),
 			infraInformer,
 		).
-		WithSync(c.sync).
+		WithSync(controllergate.Guard("AWSPlatformServiceLocationController", operatorClient, controllergate.Managed(operatorClient, c.sync, nil))).
 		WithSyncDegradedOnError(operatorClient).
 		ResyncEvery(time.Minute).
 		ToController("AWSPlatformServiceLocationController", recorder)
@@ -58,35 +67,17 @@ func (c AWSPlatformServiceLocationController) sync(ctx context.Context, syncCtx
 		return err
 	}
 
-	currentInfra := obj.DeepCopy()
-	var platformName configv1.PlatformType
-	if pstatus := currentInfra.Status.PlatformStatus; pstatus != nil {
-		platformName = pstatus.Type
-	}
-	if len(platformName) == 0 {
-		syncCtx.Recorder().Warningf("AWSPlatformServiceLocationController", "Falling back to deprecated status.platform because infrastructures.%s/cluster status.platformStatus.type is empty", configv1.GroupName)
-		platformName = currentInfra.Status.Platform
-	}
+	currentInfra := obj
+	platformName := util.PlatformType(currentInfra)
 	if platformName != configv1.AWSPlatformType {
 		return nil // nothing to do here.
 	}
 
-	if currentInfra.Spec.PlatformSpec.Type != "" && currentInfra.Spec.PlatformSpec.Type != platformName {
-		return field.Invalid(field.NewPath("spec", "platformSpec", "type"), currentInfra.Spec.PlatformSpec.Type, fmt.Sprint("non AWS platform type set in specification"))
-	}
-
-	var services []configv1.AWSServiceEndpoint
-	if currentInfra.Spec.PlatformSpec.AWS != nil {
-		services = append(services, currentInfra.Spec.PlatformSpec.AWS.ServiceEndpoints...)
-	}
-
-	if err := validateServiceEndpoints(services); err != nil {
+	services, err := ServiceEndpoints(currentInfra)
+	if err != nil {
 		syncCtx.Recorder().Warningf("AWSPlatformServiceLocationController", "Invalid spec.platformSpec.aws.serviceEndpoints provided for infrastructures.%s/cluster", configv1.GroupName)
 		return err
 	}
-	sort.Slice(services, func(i, j int) bool {
-		return services[i].Name < services[j].Name
-	})
 
 	var existingServices []configv1.AWSServiceEndpoint
 	if currentInfra.Status.PlatformStatus != nil && currentInfra.Status.PlatformStatus.AWS != nil {
@@ -96,17 +87,50 @@ func (c AWSPlatformServiceLocationController) sync(ctx context.Context, syncCtx
 		return nil // nothing to do now
 	}
 
-	if currentInfra.Status.PlatformStatus == nil {
-		currentInfra.Status.PlatformStatus = &configv1.PlatformStatus{}
+	if len(services) == 0 {
+		// an apply without the list only removes it once no other manager owns it, e.g. when it was written
+		// with UpdateStatus, so the endpoints are removed with a patch that fails if they changed meanwhile.
+		return c.removeServiceEndpoints(ctx, existingServices)
 	}
-	if currentInfra.Status.PlatformStatus.AWS == nil {
-		currentInfra.Status.PlatformStatus.AWS = &configv1.AWSPlatformStatus{}
+
+	aws := applyconfigv1.AWSPlatformStatus()
+	for _, service := range services {
+		aws.WithServiceEndpoints(applyconfigv1.AWSServiceEndpoint().WithName(service.Name).WithURL(service.URL))
 	}
-	currentInfra.Status.PlatformStatus.AWS.ServiceEndpoints = services
-	_, err = c.infraClient.UpdateStatus(ctx, currentInfra, metav1.UpdateOptions{})
+	return infrastructurestatus.Apply(ctx, c.infraClient, syncCtx.Recorder(), fieldManager,
+		applyconfigv1.InfrastructureStatus().WithPlatformStatus(applyconfigv1.PlatformStatus().WithAWS(aws)))
+}
+
+func (c AWSPlatformServiceLocationController) removeServiceEndpoints(ctx context.Context, existing []configv1.AWSServiceEndpoint) error {
+	const path = "/status/platformStatus/aws/serviceEndpoints"
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": path, "value": existing},
+		{"op": "remove", "path": path},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.infraClient.Patch(ctx, "cluster", types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager}, "status")
 	return err
 }
 
+// ServiceEndpoints returns the validated spec.platformSpec.aws.serviceEndpoints of infra sorted by name, as they are
+// written to status.platformStatus.aws.serviceEndpoints. It is also used to render the Infrastructure status at
+// bootstrap.
+func ServiceEndpoints(infra *configv1.Infrastructure) ([]configv1.AWSServiceEndpoint, error) {
+	var services []configv1.AWSServiceEndpoint
+	if infra.Spec.PlatformSpec.AWS != nil {
+		services = append(services, infra.Spec.PlatformSpec.AWS.ServiceEndpoints...)
+	}
+	if err := validateServiceEndpoints(services); err != nil {
+		return nil, err
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
 func validateServiceEndpoints(endpoints []configv1.AWSServiceEndpoint) error {
 	fldPath := field.NewPath("spec", "platformSpec", "aws", "serviceEndpoints")
 
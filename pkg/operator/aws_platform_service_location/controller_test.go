@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	clocktesting "k8s.io/utils/clock/testing"
@@ -66,13 +67,6 @@ func Test_sync(t *testing.T) {
 		expectedActions:  0,
 		expectedServices: nil,
 		expectedErr:      "",
-	}, {
-		obj: modifier(basicObj, func(i *configv1.Infrastructure) {
-			i.Spec.PlatformSpec.Type = configv1.NonePlatformType
-		}),
-		expectedActions:  0,
-		expectedServices: nil,
-		expectedErr:      `spec\.platformSpec\.type: Invalid value: "None": non AWS platform type set in specification`,
 	}, {
 		obj: modifier(basicObj, func(i *configv1.Infrastructure) {
 			i.Spec.PlatformSpec.AWS = &configv1.AWSPlatformSpec{}
@@ -176,7 +170,7 @@ func Test_sync(t *testing.T) {
 			if err := indexer.Add(tc.obj); err != nil {
 				t.Fatal(err.Error())
 			}
-			fake := configfakeclient.NewSimpleClientset(tc.obj)
+			fake := configfakeclient.NewClientset(tc.obj)
 			ctrl := AWSPlatformServiceLocationController{
 				infraClient: fake.ConfigV1().Infrastructures(),
 				infraLister: configv1listers.NewInfrastructureLister(indexer),
@@ -191,15 +185,25 @@ func Test_sync(t *testing.T) {
 			}
 			assert.Equal(t, tc.expectedActions, len(fake.Actions()))
 
-			var services []configv1.AWSServiceEndpoint
-			if tc.obj.Status.PlatformStatus != nil && tc.obj.Status.PlatformStatus.AWS != nil {
-				services = tc.obj.Status.PlatformStatus.AWS.ServiceEndpoints
-			}
 			for _, a := range fake.Actions() {
-				obj := a.(ktesting.UpdateAction).GetObject().(*configv1.Infrastructure)
-				if obj.Status.PlatformStatus != nil && obj.Status.PlatformStatus.AWS != nil {
-					services = obj.Status.PlatformStatus.AWS.ServiceEndpoints
+				patch := a.(ktesting.PatchAction)
+				if len(tc.expectedServices) > 0 {
+					assert.Equal(t, types.ApplyPatchType, patch.GetPatchType())
+				} else {
+					assert.Equal(t, types.JSONPatchType, patch.GetPatchType())
 				}
+				assert.Equal(t, "status", patch.GetSubresource())
+			}
+
+			got, err := fake.ConfigV1().Infrastructures().Get(context.TODO(), tc.obj.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var services []configv1.AWSServiceEndpoint
+			if got.Status.PlatformStatus != nil && got.Status.PlatformStatus.AWS != nil {
+				services = got.Status.PlatformStatus.AWS.ServiceEndpoints
+				// fields owned by other managers are left alone
+				assert.Equal(t, "us-east-1", got.Status.PlatformStatus.AWS.Region)
 			}
 			assert.EqualValues(t, tc.expectedServices, services)
 		})
@@ -0,0 +1,96 @@
+// Package controllergate lets the operator Config pause controllers without restarting the operator: Guard skips the
+// controllers listed in spec.unsupportedConfigOverrides.disabledControllers, and Managed pauses the controllers that
+// write cluster state while spec.managementState is not Managed.
+package controllergate
+
+import (
+	"context"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/klog/v2"
+)
+
+// InvalidOverridesConditionType is the operator condition reporting that spec.unsupportedConfigOverrides cannot be
+// parsed. It is shared by all guarded controllers.
+const InvalidOverridesConditionType = "UnsupportedConfigOverridesDegraded"
+
+// Guard wraps the sync function of the named controller so that it is skipped while the controller is listed in
+// spec.unsupportedConfigOverrides.disabledControllers of the operator Config.
+// A disabled controller is reported in the <controllerName>Disabled condition. The overrides are read on every sync,
+// so controllers should watch the operator client informer to react to changes without a restart.
+// Overrides that cannot be parsed disable no controller, they are reported in the InvalidOverridesConditionType
+// condition.
+func Guard(controllerName string, operatorClient operatorv1helpers.OperatorClient, sync factory.SyncFunc) factory.SyncFunc {
+	return func(ctx context.Context, syncCtx factory.SyncContext) error {
+		spec, status, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			return err
+		}
+		overrides, parseErr := operatorclient.GetUnsupportedConfigOverrides(spec)
+		if err := reportInvalidOverrides(ctx, operatorClient, syncCtx, status, parseErr); err != nil {
+			return err
+		}
+		if parseErr != nil {
+			overrides = &operatorclient.UnsupportedConfigOverrides{}
+		}
+
+		conditionType := controllerName + "Disabled"
+		existing := operatorv1helpers.FindOperatorCondition(status.Conditions, conditionType)
+		if overrides.IsControllerDisabled(controllerName) {
+			if existing == nil || existing.Status != operatorv1.ConditionTrue {
+				syncCtx.Recorder().Eventf("ControllerDisabled", "%s disabled by spec.unsupportedConfigOverrides", controllerName)
+				if err := applyCondition(ctx, operatorClient, controllerName, applyoperatorv1.OperatorCondition().
+					WithType(conditionType).
+					WithStatus(operatorv1.ConditionTrue).
+					WithReason("DisabledByOverride").
+					WithMessage("The controller is listed in spec.unsupportedConfigOverrides.disabledControllers")); err != nil {
+					return err
+				}
+			}
+			klog.V(4).Infof("%s: skipping sync, disabled by spec.unsupportedConfigOverrides", controllerName)
+			return nil
+		}
+
+		if existing != nil && existing.Status != operatorv1.ConditionFalse {
+			syncCtx.Recorder().Eventf("ControllerEnabled", "%s enabled", controllerName)
+			if err := applyCondition(ctx, operatorClient, controllerName, applyoperatorv1.OperatorCondition().
+				WithType(conditionType).
+				WithStatus(operatorv1.ConditionFalse).
+				WithReason("AsExpected")); err != nil {
+				return err
+			}
+		}
+		return sync(ctx, syncCtx)
+	}
+}
+
+// reportInvalidOverrides updates the InvalidOverridesConditionType condition when parseErr changes it, so that the
+// guarded controllers report it once.
+func reportInvalidOverrides(ctx context.Context, operatorClient operatorv1helpers.OperatorClient, syncCtx factory.SyncContext, status *operatorv1.OperatorStatus, parseErr error) error {
+	existing := operatorv1helpers.FindOperatorCondition(status.Conditions, InvalidOverridesConditionType)
+	condition := applyoperatorv1.OperatorCondition().WithType(InvalidOverridesConditionType)
+	switch {
+	case parseErr == nil && (existing == nil || existing.Status == operatorv1.ConditionFalse):
+		return nil
+	case parseErr == nil:
+		condition = condition.WithStatus(operatorv1.ConditionFalse).WithReason("AsExpected")
+	case existing != nil && existing.Status == operatorv1.ConditionTrue && existing.Message == parseErr.Error():
+		return nil
+	default:
+		syncCtx.Recorder().Warningf("InvalidUnsupportedConfigOverrides", "Ignoring spec.unsupportedConfigOverrides: %v", parseErr)
+		condition = condition.WithStatus(operatorv1.ConditionTrue).WithReason("InvalidOverrides").WithMessage(parseErr.Error())
+	}
+	return operatorClient.ApplyOperatorStatus(ctx,
+		factory.ControllerFieldManager("ControllerGate", "unsupportedConfigOverrides"),
+		applyoperatorv1.OperatorStatus().WithConditions(condition))
+}
+
+func applyCondition(ctx context.Context, operatorClient operatorv1helpers.OperatorClient, controllerName string, condition *applyoperatorv1.OperatorConditionApplyConfiguration) error {
+	return operatorClient.ApplyOperatorStatus(ctx,
+		factory.ControllerFieldManager(controllerName, "controllerGate"),
+		applyoperatorv1.OperatorStatus().WithConditions(condition))
+}
@@ -0,0 +1,103 @@
+package controllergate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestGuard(t *testing.T) {
+	cases := []struct {
+		name       string
+		overrides  string
+		conditions []operatorv1.OperatorCondition
+
+		synced    bool
+		condition operatorv1.ConditionStatus
+	}{{
+		name:   "enabled",
+		synced: true,
+	}, {
+		name:      "other controller disabled",
+		overrides: `{"disabledControllers":["OtherController"]}`,
+		synced:    true,
+	}, {
+		name:      "disabled",
+		overrides: `{"disabledControllers":["TestController"]}`,
+		condition: operatorv1.ConditionTrue,
+	}, {
+		name:       "re-enabled",
+		conditions: []operatorv1.OperatorCondition{{Type: "TestControllerDisabled", Status: operatorv1.ConditionTrue}},
+		synced:     true,
+		condition:  operatorv1.ConditionFalse,
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			spec := &operatorv1.OperatorSpec{UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)}}
+			operatorClient := operatorv1helpers.NewFakeOperatorClient(spec, &operatorv1.OperatorStatus{Conditions: test.conditions}, nil)
+
+			synced := false
+			sync := Guard("TestController", operatorClient, func(ctx context.Context, syncCtx factory.SyncContext) error {
+				synced = true
+				return nil
+			})
+			syncCtx := factory.NewSyncContext("TestController", events.NewInMemoryRecorder("TestController", clocktesting.NewFakePassiveClock(time.Now())))
+			require.NoError(t, sync(context.TODO(), syncCtx))
+			assert.Equal(t, test.synced, synced)
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			require.NoError(t, err)
+			condition := operatorv1helpers.FindOperatorCondition(status.Conditions, "TestControllerDisabled")
+			if len(test.condition) == 0 {
+				assert.Nil(t, condition)
+				return
+			}
+			require.NotNil(t, condition)
+			assert.Equal(t, test.condition, condition.Status)
+		})
+	}
+}
+
+func TestGuard_invalidOverrides(t *testing.T) {
+	spec := &operatorv1.OperatorSpec{UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(`{"disabledControllers":"TestController"}`)}}
+	operatorClient := operatorv1helpers.NewFakeOperatorClient(spec, &operatorv1.OperatorStatus{}, nil)
+	recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Now()))
+
+	syncs := 0
+	for _, controllerName := range []string{"TestController", "OtherController"} {
+		sync := Guard(controllerName, operatorClient, func(ctx context.Context, syncCtx factory.SyncContext) error {
+			syncs++
+			return nil
+		})
+		require.NoError(t, sync(context.TODO(), factory.NewSyncContext(controllerName, recorder)))
+	}
+	assert.Equal(t, 2, syncs, "invalid overrides disable no controller")
+	require.Len(t, recorder.Events(), 1, "invalid overrides are reported once")
+	assert.Equal(t, "InvalidUnsupportedConfigOverrides", recorder.Events()[0].Reason)
+
+	condition := func() *operatorv1.OperatorCondition {
+		_, status, _, err := operatorClient.GetOperatorState()
+		require.NoError(t, err)
+		return operatorv1helpers.FindOperatorCondition(status.Conditions, InvalidOverridesConditionType)
+	}
+	require.NotNil(t, condition())
+	assert.Equal(t, operatorv1.ConditionTrue, condition().Status)
+	assert.Contains(t, condition().Message, "unable to parse spec.unsupportedConfigOverrides")
+
+	_, _, resourceVersion, err := operatorClient.GetOperatorState()
+	require.NoError(t, err)
+	_, _, err = operatorClient.UpdateOperatorSpec(context.TODO(), resourceVersion, &operatorv1.OperatorSpec{})
+	require.NoError(t, err)
+	sync := Guard("TestController", operatorClient, func(ctx context.Context, syncCtx factory.SyncContext) error { return nil })
+	require.NoError(t, sync(context.TODO(), factory.NewSyncContext("TestController", recorder)))
+	assert.Equal(t, operatorv1.ConditionFalse, condition().Status)
+}
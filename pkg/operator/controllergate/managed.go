@@ -0,0 +1,35 @@
+package controllergate
+
+import (
+	"context"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/klog/v2"
+)
+
+// Managed wraps the sync function of a controller that writes cluster state so that it only runs while the
+// spec.managementState of the operator Config is Managed. While the operator is Removed, removed is run instead to
+// clean up what the controller created, if it is not nil. Nothing is run for Unmanaged or unknown states.
+func Managed(operatorClient operatorv1helpers.OperatorClient, sync, removed factory.SyncFunc) factory.SyncFunc {
+	return func(ctx context.Context, syncCtx factory.SyncContext) error {
+		spec, _, _, err := operatorClient.GetOperatorState()
+		if err != nil {
+			return err
+		}
+
+		switch spec.ManagementState {
+		case operatorv1.Managed, "":
+			return sync(ctx, syncCtx)
+		case operatorv1.Removed:
+			if removed == nil {
+				return nil
+			}
+			return removed(ctx, syncCtx)
+		default:
+			klog.V(4).Infof("Skipping sync, managementState is %q", spec.ManagementState)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package controllergate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestManaged(t *testing.T) {
+	cases := []struct {
+		managementState operatorv1.ManagementState
+		withRemove      bool
+		ran             string
+	}{
+		{managementState: "", ran: "sync"},
+		{managementState: operatorv1.Managed, ran: "sync"},
+		{managementState: operatorv1.Unmanaged},
+		{managementState: operatorv1.Removed},
+		{managementState: operatorv1.Removed, withRemove: true, ran: "remove"},
+		{managementState: operatorv1.Force, withRemove: true},
+	}
+	for _, test := range cases {
+		t.Run(string(test.managementState), func(t *testing.T) {
+			operatorClient := operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: test.managementState}, &operatorv1.OperatorStatus{}, nil)
+
+			ran := ""
+			syncFn := func(name string) factory.SyncFunc {
+				return func(ctx context.Context, syncCtx factory.SyncContext) error {
+					ran = name
+					return nil
+				}
+			}
+			var remove factory.SyncFunc
+			if test.withRemove {
+				remove = syncFn("remove")
+			}
+
+			syncCtx := factory.NewSyncContext("TestController", events.NewInMemoryRecorder("TestController", clocktesting.NewFakePassiveClock(time.Now())))
+			require.NoError(t, Managed(operatorClient, syncFn("sync"), remove)(context.TODO(), syncCtx))
+			assert.Equal(t, test.ran, ran)
+		})
+	}
+}
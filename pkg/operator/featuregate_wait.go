@@ -0,0 +1,67 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const featureGateDetectionConditionType = "FeatureGateDetectionDegraded"
+
+// runWhenFeatureGatesObserved starts the controllers once the initial feature gates have been observed.
+// If that takes longer than timeout, the FeatureGateDetectionDegraded condition reports the controllers that
+// are waiting until the feature gates appear. It returns when the controllers are started or ctx is done.
+func runWhenFeatureGatesObserved(ctx context.Context, observed <-chan struct{}, timeout time.Duration, clock clock.Clock,
+	operatorClient v1helpers.OperatorClient, controllers ...factory.Controller) {
+	timer := clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-observed:
+	case <-ctx.Done():
+		return
+	case <-timer.C():
+		names := make([]string, 0, len(controllers))
+		for _, c := range controllers {
+			names = append(names, c.Name())
+		}
+		message := fmt.Sprintf("Timed out waiting for FeatureGate detection, %s will start once feature gates are observed", strings.Join(names, ", "))
+		klog.Error(message)
+		reportFeatureGateDetection(ctx, operatorClient, applyoperatorv1.OperatorCondition().
+			WithType(featureGateDetectionConditionType).
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("WaitingForFeatureGates").
+			WithMessage(message))
+
+		select {
+		case <-observed:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	klog.Info("FeatureGates initialized")
+	reportFeatureGateDetection(ctx, operatorClient, applyoperatorv1.OperatorCondition().
+		WithType(featureGateDetectionConditionType).
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason("AsExpected"))
+	for _, c := range controllers {
+		go c.Run(ctx, 1)
+	}
+}
+
+func reportFeatureGateDetection(ctx context.Context, operatorClient v1helpers.OperatorClient, condition *applyoperatorv1.OperatorConditionApplyConfiguration) {
+	err := operatorClient.ApplyOperatorStatus(ctx,
+		factory.ControllerFieldManager("FeatureGateDetection", "waitForFeatureGates"),
+		applyoperatorv1.OperatorStatus().WithConditions(condition))
+	if err != nil {
+		klog.Warningf("Unable to update %s condition: %v", featureGateDetectionConditionType, err)
+	}
+}
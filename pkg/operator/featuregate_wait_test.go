@@ -0,0 +1,74 @@
+package operator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+type fakeController struct {
+	name    string
+	started atomic.Bool
+}
+
+func (c *fakeController) Run(ctx context.Context, workers int) { c.started.Store(true) }
+func (c *fakeController) Sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	return nil
+}
+func (c *fakeController) Name() string { return c.name }
+
+// notifyingOperatorClient signals every status apply, the fake operator client is not safe for concurrent use.
+type notifyingOperatorClient struct {
+	v1helpers.OperatorClient
+	applied chan struct{}
+}
+
+func (c *notifyingOperatorClient) ApplyOperatorStatus(ctx context.Context, fieldManager string, applyConfiguration *applyoperatorv1.OperatorStatusApplyConfiguration) error {
+	defer func() { c.applied <- struct{}{} }()
+	return c.OperatorClient.ApplyOperatorStatus(ctx, fieldManager, applyConfiguration)
+}
+
+func TestRunWhenFeatureGatesObserved(t *testing.T) {
+	operatorClient := &notifyingOperatorClient{
+		OperatorClient: v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+		applied:        make(chan struct{}, 2),
+	}
+	controller := &fakeController{name: "KubeCloudConfigController"}
+	observed := make(chan struct{})
+	fakeClock := clocktesting.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runWhenFeatureGatesObserved(context.TODO(), observed, 5*time.Minute, fakeClock, operatorClient, controller)
+	}()
+	require.Eventually(t, fakeClock.HasWaiters, 5*time.Second, 10*time.Millisecond)
+	fakeClock.Step(5 * time.Minute)
+
+	condition := func() *operatorv1.OperatorCondition {
+		_, status, _, err := operatorClient.GetOperatorState()
+		require.NoError(t, err)
+		return v1helpers.FindOperatorCondition(status.Conditions, featureGateDetectionConditionType)
+	}
+	<-operatorClient.applied
+	require.NotNil(t, condition())
+	assert.Equal(t, operatorv1.ConditionTrue, condition().Status)
+	assert.Equal(t, "WaitingForFeatureGates", condition().Reason)
+	assert.Contains(t, condition().Message, "KubeCloudConfigController")
+	assert.False(t, controller.started.Load())
+
+	close(observed)
+	<-done
+	<-operatorClient.applied
+	assert.Equal(t, operatorv1.ConditionFalse, condition().Status)
+	require.Eventually(t, controller.started.Load, 5*time.Second, 10*time.Millisecond)
+}
@@ -13,6 +13,7 @@ import (
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	v1 "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/status"
@@ -63,7 +64,7 @@ func NewFeatureGateController(
 			featureGatesInformer.Informer(),
 			clusterVersionInformer.Informer(),
 		).
-		WithSync(c.sync).
+		WithSync(controllergate.Managed(operatorClient, c.sync, nil)).
 		WithSyncDegradedOnError(operatorClient).
 		ResyncEvery(time.Minute).
 		ToController("FeatureGateController", eventRecorder)
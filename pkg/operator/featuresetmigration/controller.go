@@ -0,0 +1,146 @@
+package featuresetmigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blang/semver/v4"
+	configv1 "github.com/openshift/api/config/v1"
+	applyconfigurationsconfigv1 "github.com/openshift/client-go/config/applyconfigurations/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	v1 "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
+	"github.com/openshift/cluster-config-operator/pkg/operator/migration"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+)
+
+// FeatureSetMigrationController rewrites the featureSet of the cluster FeatureGate according to a table of rules.
+// Rules are evaluated in order, so a rule sees the featureSet produced by the rules before it. In dry-run nothing is
+// written and every rule is evaluated against the current featureSet.
+type FeatureSetMigrationController struct {
+	featureGatesClient configv1client.FeatureGatesGetter
+	featureGatesLister configlistersv1.FeatureGateLister
+	operatorVersion    string
+	rules              []rule
+}
+
+type rule struct {
+	Rule
+	migrator *migration.Migrator
+}
+
+func NewFeatureSetMigrationController(operatorClient operatorv1helpers.OperatorClient,
+	featureGatesClient configv1client.FeatureGatesGetter, featureGatesInformer v1.FeatureGateInformer,
+	operatorVersion string, rules []Rule,
+	clock clock.PassiveClock, eventRecorder events.Recorder) factory.Controller {
+	c := &FeatureSetMigrationController{
+		featureGatesClient: featureGatesClient,
+		featureGatesLister: featureGatesInformer.Lister(),
+		operatorVersion:    operatorVersion,
+	}
+	for _, r := range rules {
+		c.rules = append(c.rules, rule{Rule: r, migrator: migration.NewMigrator(r.Name, operatorClient, clock)})
+	}
+
+	return factory.New().
+		WithInformers(
+			operatorClient.Informer(),
+			featureGatesInformer.Informer(),
+		).
+		WithSync(controllergate.Guard("FeatureSetMigrationController", operatorClient, controllergate.Managed(operatorClient, c.sync, nil))).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController("FeatureSetMigrationController", eventRecorder)
+}
+
+func (c FeatureSetMigrationController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	featureGates, err := c.featureGatesLister.Get("cluster")
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get FeatureGate: %w", err)
+	}
+
+	return c.syncFeatureGate(ctx, syncCtx.Recorder(), featureGates)
+}
+
+func (c FeatureSetMigrationController) syncFeatureGate(ctx context.Context, recorder events.Recorder, featureGates *configv1.FeatureGate) error {
+	featureSet := featureGates.Spec.FeatureSet
+	for _, r := range c.rules {
+		if reason, message := c.inactive(r.Rule); len(reason) > 0 {
+			if err := r.migrator.Inactive(ctx, reason, message); err != nil {
+				return err
+			}
+			continue
+		}
+		if !r.matches(featureSet) {
+			if _, err := r.migrator.Sync(ctx, recorder, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		applied, err := r.migrator.Sync(ctx, recorder, c.change(r.Rule, featureSet))
+		if err != nil {
+			return err
+		}
+		if applied {
+			featureSet = r.To
+		}
+	}
+	return nil
+}
+
+// inactive returns the reason and message when the rule does not apply to this operator.
+func (c FeatureSetMigrationController) inactive(r Rule) (string, string) {
+	if r.BuildCondition != nil && !r.BuildCondition() {
+		return "NotApplicable", "The migration does not apply to this build of the operator"
+	}
+	if len(r.ExpiresInVersion) == 0 {
+		return "", ""
+	}
+	expiresIn, err := semver.ParseTolerant(r.ExpiresInVersion)
+	if err != nil {
+		return "InvalidRule", fmt.Sprintf("Unable to parse expiry version %q: %v", r.ExpiresInVersion, err)
+	}
+	current, err := semver.ParseTolerant(c.operatorVersion)
+	if err != nil {
+		// development builds don't carry a release version, keep the rule active
+		return "", ""
+	}
+	if current.GTE(expiresIn) {
+		return "Expired", fmt.Sprintf("The migration expired in version %s", r.ExpiresInVersion)
+	}
+	return "", ""
+}
+
+func (c FeatureSetMigrationController) change(r Rule, featureSet configv1.FeatureSet) *migration.Change {
+	return &migration.Change{
+		Description: fmt.Sprintf("featuregates.%s/cluster spec.featureSet %q -> %q", configv1.GroupName, featureSet, r.To),
+		Apply: func(ctx context.Context, annotations map[string]string) error {
+			desiredFeatureGate := applyconfigurationsconfigv1.FeatureGate("cluster").
+				WithAnnotations(annotations).
+				WithSpec(
+					applyconfigurationsconfigv1.FeatureGateSpec().
+						WithFeatureSet(r.To),
+				)
+			applyOptions := metav1.ApplyOptions{
+				Force:        true,
+				FieldManager: r.Name,
+			}
+
+			if _, err := c.featureGatesClient.FeatureGates().Apply(ctx, desiredFeatureGate, applyOptions); err != nil {
+				return fmt.Errorf("unable to migrate FeatureGate to %q: %w", r.To, err)
+			}
+			return nil
+		},
+	}
+}
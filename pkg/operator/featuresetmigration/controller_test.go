@@ -0,0 +1,161 @@
+package featuresetmigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1fake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/cluster-config-operator/pkg/operator/migration"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubetesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestFeatureSetMigrationController_syncFeatureGate(t *testing.T) {
+	always := func() bool { return true }
+	never := func() bool { return false }
+
+	tests := []struct {
+		name            string
+		featureSet      configv1.FeatureSet
+		operatorVersion string
+		dryRun          bool
+		rules           []Rule
+
+		applied    []string
+		conditions map[string]string
+	}{
+		{
+			name:       "clear-latency-sensitive",
+			featureSet: "LatencySensitive",
+			rules:      []Rule{DefaultRules[0]},
+			applied: []string{
+				`{"kind":"FeatureGate","apiVersion":"config.openshift.io/v1","metadata":{"name":"cluster","annotations":{"migration.config.openshift.io/LatencySensitiveRemovalController":"2024-01-02T03:04:05Z"}},"spec":{"featureSet":""}}`,
+			},
+			conditions: map[string]string{"LatencySensitiveRemovalControllerMigrationPending": "Applied"},
+		},
+		{
+			name:       "leave-other-value",
+			featureSet: configv1.TechPreviewNoUpgrade,
+			rules:      []Rule{DefaultRules[0]},
+			conditions: map[string]string{"LatencySensitiveRemovalControllerMigrationPending": "AsExpected"},
+		},
+		{
+			name:       "migrate-empty-featureset-to-okd",
+			featureSet: "",
+			rules:      []Rule{{Name: "OKD", From: []configv1.FeatureSet{"", configv1.Default}, To: configv1.OKD, BuildCondition: always}},
+			applied: []string{
+				`{"kind":"FeatureGate","apiVersion":"config.openshift.io/v1","metadata":{"name":"cluster","annotations":{"migration.config.openshift.io/OKD":"2024-01-02T03:04:05Z"}},"spec":{"featureSet":"OKD"}}`,
+			},
+			conditions: map[string]string{"OKDMigrationPending": "Applied"},
+		},
+		{
+			name:       "build-condition-not-met",
+			featureSet: "",
+			rules:      []Rule{{Name: "OKD", From: []configv1.FeatureSet{"", configv1.Default}, To: configv1.OKD, BuildCondition: never}},
+			conditions: map[string]string{"OKDMigrationPending": "NotApplicable"},
+		},
+		{
+			name:       "chained-rules",
+			featureSet: "LatencySensitive",
+			rules: []Rule{
+				{Name: "First", From: []configv1.FeatureSet{"LatencySensitive"}, To: configv1.Default},
+				{Name: "Second", From: []configv1.FeatureSet{configv1.Default}, To: configv1.OKD},
+			},
+			applied: []string{
+				`{"kind":"FeatureGate","apiVersion":"config.openshift.io/v1","metadata":{"name":"cluster","annotations":{"migration.config.openshift.io/First":"2024-01-02T03:04:05Z"}},"spec":{"featureSet":""}}`,
+				`{"kind":"FeatureGate","apiVersion":"config.openshift.io/v1","metadata":{"name":"cluster","annotations":{"migration.config.openshift.io/Second":"2024-01-02T03:04:05Z"}},"spec":{"featureSet":"OKD"}}`,
+			},
+			conditions: map[string]string{"FirstMigrationPending": "Applied", "SecondMigrationPending": "Applied"},
+		},
+		{
+			name:       "dry-run-evaluates-current-featureset",
+			featureSet: "LatencySensitive",
+			dryRun:     true,
+			rules: []Rule{
+				{Name: "First", From: []configv1.FeatureSet{"LatencySensitive"}, To: configv1.Default},
+				{Name: "Second", From: []configv1.FeatureSet{configv1.Default}, To: configv1.OKD},
+			},
+			conditions: map[string]string{"FirstMigrationPending": "DryRun", "SecondMigrationPending": "AsExpected"},
+		},
+		{
+			name:            "expired",
+			featureSet:      "LatencySensitive",
+			operatorVersion: "4.22.1",
+			rules:           []Rule{{Name: "Old", From: []configv1.FeatureSet{"LatencySensitive"}, To: configv1.Default, ExpiresInVersion: "4.22.0"}},
+			conditions:      map[string]string{"OldMigrationPending": "Expired"},
+		},
+		{
+			name:            "not-yet-expired",
+			featureSet:      "LatencySensitive",
+			operatorVersion: "4.21.3",
+			rules:           []Rule{{Name: "Old", From: []configv1.FeatureSet{"LatencySensitive"}, To: configv1.Default, ExpiresInVersion: "4.22.0"}},
+			applied: []string{
+				`{"kind":"FeatureGate","apiVersion":"config.openshift.io/v1","metadata":{"name":"cluster","annotations":{"migration.config.openshift.io/Old":"2024-01-02T03:04:05Z"}},"spec":{"featureSet":""}}`,
+			},
+			conditions: map[string]string{"OldMigrationPending": "Applied"},
+		},
+		{
+			name:            "development-build-never-expires",
+			featureSet:      "LatencySensitive",
+			operatorVersion: "0.0.1-snapshot",
+			rules:           []Rule{{Name: "Old", From: []configv1.FeatureSet{"LatencySensitive"}, To: configv1.Default, ExpiresInVersion: "4.22.0"}},
+			applied: []string{
+				`{"kind":"FeatureGate","apiVersion":"config.openshift.io/v1","metadata":{"name":"cluster","annotations":{"migration.config.openshift.io/Old":"2024-01-02T03:04:05Z"}},"spec":{"featureSet":""}}`,
+			},
+			conditions: map[string]string{"OldMigrationPending": "Applied"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			featureGate := &configv1.FeatureGate{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.FeatureGateSpec{
+					FeatureGateSelection: configv1.FeatureGateSelection{
+						FeatureSet: tt.featureSet,
+					},
+				},
+			}
+			fakeClient := configv1fake.NewSimpleClientset(featureGate)
+			spec := &operatorv1.OperatorSpec{}
+			if tt.dryRun {
+				spec.UnsupportedConfigOverrides.Raw = []byte(`{"migration":{"dryRun":true}}`)
+			}
+			operatorClient := operatorv1helpers.NewFakeOperatorClient(spec, &operatorv1.OperatorStatus{}, nil)
+			fakeClock := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+			c := FeatureSetMigrationController{
+				featureGatesClient: fakeClient.ConfigV1(),
+				operatorVersion:    tt.operatorVersion,
+			}
+			for _, r := range tt.rules {
+				c.rules = append(c.rules, rule{Rule: r, migrator: migration.NewMigrator(r.Name, operatorClient, fakeClock)})
+			}
+			require.NoError(t, c.syncFeatureGate(context.TODO(), events.NewInMemoryRecorder("test", fakeClock), featureGate))
+
+			var applied []string
+			for _, action := range fakeClient.Actions() {
+				patchAction := action.(kubetesting.PatchAction)
+				assert.Equal(t, types.ApplyPatchType, patchAction.GetPatchType())
+				applied = append(applied, string(patchAction.GetPatch()))
+			}
+			assert.Equal(t, tt.applied, applied)
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			require.NoError(t, err)
+			conditions := map[string]string{}
+			for _, condition := range status.Conditions {
+				conditions[condition.Type] = condition.Reason
+			}
+			assert.Equal(t, tt.conditions, conditions)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package featuresetmigration
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/version"
+)
+
+// Rule rewrites the featureSet of featuregates.config.openshift.io/cluster.
+type Rule struct {
+	// Name identifies the rule. It is used as field manager, in the audit annotation and as the
+	// prefix of the <Name>MigrationPending condition.
+	Name string
+	// From lists the featureSets that are migrated.
+	From []configv1.FeatureSet
+	// To is the featureSet the matching featureSets are migrated to.
+	To configv1.FeatureSet
+	// BuildCondition optionally restricts the rule to some builds of the operator, e.g. version.IsSCOS.
+	BuildCondition func() bool
+	// ExpiresInVersion optionally disables the rule from this operator version on, once the
+	// release no longer supports upgrades from clusters that may still need the migration.
+	ExpiresInVersion string
+}
+
+// DefaultRules are the featureSet migrations run by the operator.
+// The names are kept from the controllers these rules replaced so that field managers and conditions are stable.
+var DefaultRules = []Rule{
+	{
+		// to be removed a release after we block upgrades
+		Name: "LatencySensitiveRemovalController",
+		From: []configv1.FeatureSet{"LatencySensitive"},
+		To:   configv1.Default,
+	},
+	{
+		// The installer creates FeatureGate with OKD featureset for new installations.
+		// This rule migrates existing clusters upgraded to OKD.
+		// to be removed a release after OKD upgrades are stable
+		Name:           "OKDFeatureSetMigrationController",
+		From:           []configv1.FeatureSet{"", configv1.Default},
+		To:             configv1.OKD,
+		BuildCondition: version.IsSCOS,
+	},
+}
+
+func (r Rule) matches(featureSet configv1.FeatureSet) bool {
+	for _, from := range r.From {
+		if from == featureSet {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configinformers "github.com/openshift/client-go/config/informers/externalversions"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -39,7 +40,7 @@ func NewFeatureUpgradeableController(
 	return factory.New().WithInformers(
 		operatorClient.Informer(),
 		configInformer.Config().V1().FeatureGates().Informer(),
-	).WithSync(c.sync).ToController("FeatureUpgradeableController", eventRecorder.WithComponentSuffix("feature-upgradeable"))
+	).WithSync(controllergate.Guard("FeatureUpgradeableController", operatorClient, c.sync)).ToController("FeatureUpgradeableController", eventRecorder.WithComponentSuffix("feature-upgradeable"))
 }
 
 func (c *FeatureUpgradeableController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
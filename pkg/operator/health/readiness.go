@@ -0,0 +1,188 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// featureGateControllerName is the controller that must have synced once before the operator is ready.
+	featureGateControllerName = "FeatureGateController"
+
+	// staleSyncThreshold is how long a controller may go without syncing before it is reported as stuck.
+	// Controllers that resync do so every minute.
+	staleSyncThreshold = 5 * time.Minute
+)
+
+// Readiness backs the /readyz endpoint of the operator.
+// The operator is ready once the initial FeatureGates have been observed and the FeatureGateController has
+// synced successfully, and stays ready as long as every controller keeps syncing.
+//
+// Every controller of the operator is registered with Heartbeat, which returns the operator client the controller
+// records its syncs with.
+type Readiness struct {
+	clock clock.PassiveClock
+
+	lock                        sync.Mutex
+	initialFeatureGatesObserved <-chan struct{}
+	syncs                       map[string]*syncState
+}
+
+type syncState struct {
+	// resyncs is whether the controller resyncs periodically. Controllers that only sync on changes are only
+	// required to sync once.
+	resyncs    bool
+	registered time.Time
+	lastSync   time.Time
+	everSynced bool
+	succeeded  bool
+}
+
+// NewReadiness returns a Readiness that is not ready yet.
+func NewReadiness(clock clock.PassiveClock) *Readiness {
+	return &Readiness{
+		clock: clock,
+		syncs: map[string]*syncState{},
+	}
+}
+
+// HealthChecks returns the checks to register with the operator's server.
+// The health checkers of the server are shared by /healthz, /livez and /readyz, so the checks only fail
+// on /readyz requests to avoid restarting an operator that is still starting up.
+func (r *Readiness) HealthChecks() []healthz.HealthChecker {
+	return []healthz.HealthChecker{
+		readyzCheck{name: "feature-gates-initialized", check: r.featureGatesInitialized},
+		readyzCheck{name: "controller-syncs", check: r.controllersSyncing},
+	}
+}
+
+// WaitForInitialFeatureGates makes readiness depend on observed being closed.
+func (r *Readiness) WaitForInitialFeatureGates(observed <-chan struct{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.initialFeatureGatesObserved = observed
+}
+
+// Heartbeat registers the named controller and returns the operator client it must be built with. Every sync of the
+// controllers reads the operator state first, directly or through controllergate, and each read is recorded as a sync
+// heartbeat. The controller reports whether a sync succeeded in its <controllerName>Degraded condition, see
+// factory.Factory.WithSyncDegradedOnError.
+// A controller that resyncs is reported as not syncing once it has missed several resyncs, any other controller
+// once it has not synced since it was registered for as long.
+func (r *Readiness) Heartbeat(controllerName string, resyncs bool, operatorClient v1helpers.OperatorClient) v1helpers.OperatorClient {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.syncs[controllerName] = &syncState{resyncs: resyncs, registered: r.clock.Now()}
+	return &heartbeatOperatorClient{OperatorClient: operatorClient, readiness: r, controllerName: controllerName}
+}
+
+func (r *Readiness) featureGatesInitialized() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.initialFeatureGatesObserved == nil {
+		return fmt.Errorf("feature gate detection has not started")
+	}
+	select {
+	case <-r.initialFeatureGatesObserved:
+	default:
+		return fmt.Errorf("initial feature gates have not been observed")
+	}
+	if state := r.syncs[featureGateControllerName]; state == nil || !state.succeeded {
+		return fmt.Errorf("%s has not synced successfully", featureGateControllerName)
+	}
+	return nil
+}
+
+func (r *Readiness) controllersSyncing() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.clock.Now()
+	var stale []string
+	for name, state := range r.syncs {
+		switch {
+		case !state.everSynced:
+			if since := now.Sub(state.registered); since > staleSyncThreshold {
+				stale = append(stale, fmt.Sprintf("%s has not synced in %s", name, since.Round(time.Second)))
+			}
+		case state.resyncs:
+			if since := now.Sub(state.lastSync); since > staleSyncThreshold {
+				stale = append(stale, fmt.Sprintf("%s last synced %s ago", name, since.Round(time.Second)))
+			}
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("controllers not syncing: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+func (r *Readiness) recordSync(controllerName string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state := r.syncs[controllerName]
+	state.lastSync = r.clock.Now()
+	state.everSynced = true
+}
+
+func (r *Readiness) recordResult(controllerName string, degraded bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state := r.syncs[controllerName]
+	state.succeeded = state.succeeded || !degraded
+}
+
+// heartbeatOperatorClient records the syncs of a single controller.
+type heartbeatOperatorClient struct {
+	v1helpers.OperatorClient
+	readiness      *Readiness
+	controllerName string
+}
+
+func (c *heartbeatOperatorClient) GetOperatorState() (*operatorv1.OperatorSpec, *operatorv1.OperatorStatus, string, error) {
+	c.readiness.recordSync(c.controllerName)
+	return c.OperatorClient.GetOperatorState()
+}
+
+func (c *heartbeatOperatorClient) ApplyOperatorStatus(ctx context.Context, fieldManager string, applyConfiguration *applyoperatorv1.OperatorStatusApplyConfiguration) error {
+	if applyConfiguration != nil {
+		for _, condition := range applyConfiguration.Conditions {
+			if condition.Type != nil && *condition.Type == c.controllerName+"Degraded" && condition.Status != nil {
+				c.readiness.recordResult(c.controllerName, *condition.Status == operatorv1.ConditionTrue)
+			}
+		}
+	}
+	return c.OperatorClient.ApplyOperatorStatus(ctx, fieldManager, applyConfiguration)
+}
+
+// readyzCheck is a health check that only fails for /readyz requests.
+type readyzCheck struct {
+	name  string
+	check func() error
+}
+
+func (c readyzCheck) Name() string {
+	return c.name
+}
+
+func (c readyzCheck) Check(req *http.Request) error {
+	if req == nil || req.URL == nil || !strings.HasPrefix(req.URL.Path, "/readyz") {
+		return nil
+	}
+	return c.check()
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestReadiness(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	readiness := NewReadiness(fakeClock)
+	fakeOperatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	operatorClients := map[string]v1helpers.OperatorClient{
+		"FeatureGateController":     readiness.Heartbeat("FeatureGateController", true, fakeOperatorClient),
+		"KubeCloudConfigController": readiness.Heartbeat("KubeCloudConfigController", true, fakeOperatorClient),
+		"LoggingSyncer":             readiness.Heartbeat("LoggingSyncer", false, fakeOperatorClient),
+	}
+
+	check := func(path string) map[string]string {
+		failures := map[string]string{}
+		for _, c := range readiness.HealthChecks() {
+			if err := c.Check(httptest.NewRequest("GET", path, nil)); err != nil {
+				failures[c.Name()] = err.Error()
+			}
+		}
+		return failures
+	}
+	// sync reads the operator state and reports the Degraded condition like a controller built with
+	// WithSyncDegradedOnError.
+	sync := func(controllerName string, status operatorv1.ConditionStatus) {
+		operatorClient := operatorClients[controllerName]
+		_, _, _, err := operatorClient.GetOperatorState()
+		require.NoError(t, err)
+		require.NoError(t, operatorClient.ApplyOperatorStatus(context.TODO(), factory.ControllerFieldManager(controllerName, "reportDegraded"),
+			applyoperatorv1.OperatorStatus().WithConditions(applyoperatorv1.OperatorCondition().
+				WithType(controllerName+"Degraded").
+				WithStatus(status))))
+	}
+
+	assert.Equal(t, map[string]string{"feature-gates-initialized": "feature gate detection has not started"}, check("/readyz"))
+
+	observed := make(chan struct{})
+	readiness.WaitForInitialFeatureGates(observed)
+	assert.Equal(t, map[string]string{"feature-gates-initialized": "initial feature gates have not been observed"}, check("/readyz"))
+
+	close(observed)
+	sync("FeatureGateController", operatorv1.ConditionTrue)
+	assert.Equal(t, map[string]string{"feature-gates-initialized": "FeatureGateController has not synced successfully"}, check("/readyz"))
+
+	sync("FeatureGateController", operatorv1.ConditionFalse)
+	assert.Empty(t, check("/readyz"))
+
+	// registered controllers that never sync are reported
+	fakeClock.Step(6 * time.Minute)
+	sync("FeatureGateController", operatorv1.ConditionFalse)
+	assert.Equal(t, map[string]string{"controller-syncs": "controllers not syncing: KubeCloudConfigController has not synced in 6m0s, LoggingSyncer has not synced in 6m0s"}, check("/readyz/controller-syncs"))
+
+	// a controller reporting degraded is still syncing, controllers that don't resync only have to sync once
+	sync("KubeCloudConfigController", operatorv1.ConditionTrue)
+	sync("LoggingSyncer", operatorv1.ConditionFalse)
+	assert.Empty(t, check("/readyz"))
+
+	fakeClock.Step(4 * time.Minute)
+	sync("FeatureGateController", operatorv1.ConditionTrue)
+	fakeClock.Step(2 * time.Minute)
+	assert.Equal(t, map[string]string{"controller-syncs": "controllers not syncing: KubeCloudConfigController last synced 6m0s ago"}, check("/readyz/controller-syncs"))
+
+	// liveness is not affected
+	assert.Empty(t, check("/healthz"))
+	assert.Empty(t, check("/livez"))
+}
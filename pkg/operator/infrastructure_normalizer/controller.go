@@ -0,0 +1,133 @@
+package infrastructure_normalizer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	applyconfigv1 "github.com/openshift/client-go/config/applyconfigurations/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
+	"github.com/openshift/cluster-config-operator/pkg/operator/infrastructurestatus"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+)
+
+const fieldManager = "InfrastructureNormalizerController"
+
+// InfrastructureNormalizerController owns the consistency of the platform type of the
+// `infrastructure.config.openshift.io/v1` `cluster` object:
+//   - `status.platformStatus.type` is set from the deprecated `status.platform` when it is empty, and the other way around,
+//   - `status.platform` and `status.platformStatus.type` must agree,
+//   - `spec.platformSpec.type`, when set, must agree with the status.
+//
+// Disagreements cannot be resolved automatically and are reported as Degraded. Only the two status fields are applied.
+// Other controllers read the platform type with util.PlatformType.
+type InfrastructureNormalizerController struct {
+	infraClient configv1client.InfrastructureInterface
+	infraLister configv1listers.InfrastructureLister
+}
+
+// NewController returns an InfrastructureNormalizerController
+func NewController(operatorClient operatorv1helpers.OperatorClient,
+	infraClient configv1client.InfrastructuresGetter, infraLister configv1listers.InfrastructureLister, infraInformer cache.SharedIndexInformer,
+	recorder events.Recorder) factory.Controller {
+	c := &InfrastructureNormalizerController{
+		infraClient: infraClient.Infrastructures(),
+		infraLister: infraLister,
+	}
+	return factory.New().
+		WithInformers(
+			operatorClient.Informer(),
+			infraInformer,
+		).
+		WithSync(controllergate.Guard("InfrastructureNormalizerController", operatorClient, controllergate.Managed(operatorClient, c.sync, nil))).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController("InfrastructureNormalizerController", recorder)
+}
+
+func (c InfrastructureNormalizerController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	obj, err := c.infraLister.Get("cluster")
+	if errors.IsNotFound(err) {
+		syncCtx.Recorder().Warningf("InfrastructureNormalizerController", "Required infrastructures.%s/cluster not found", configv1.GroupName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	currentInfra := obj.DeepCopy()
+	changed, err := Normalize(currentInfra)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := infrastructurestatus.Apply(ctx, c.infraClient, syncCtx.Recorder(), fieldManager,
+		applyconfigv1.InfrastructureStatus().
+			WithPlatform(currentInfra.Status.Platform).
+			WithPlatformStatus(applyconfigv1.PlatformStatus().WithType(currentInfra.Status.PlatformStatus.Type))); err != nil {
+		return err
+	}
+	syncCtx.Recorder().Eventf("InfrastructureNormalizerController", "Set status.platform and status.platformStatus.type of infrastructures.%s/cluster to %s", configv1.GroupName, currentInfra.Status.Platform)
+	return nil
+}
+
+// Normalize fills the empty platform type fields of the infra status and validates that all platform types agree.
+// It returns true if infra was changed. It is also used to render the Infrastructure status at bootstrap.
+func Normalize(infra *configv1.Infrastructure) (bool, error) {
+	statusPath := field.NewPath("status")
+	platform := infra.Status.Platform
+	var platformStatusType configv1.PlatformType
+	if infra.Status.PlatformStatus != nil {
+		platformStatusType = infra.Status.PlatformStatus.Type
+	}
+
+	switch {
+	case len(platform) == 0 && len(platformStatusType) == 0:
+		// nothing to normalize, e.g. the installer has not populated the status yet
+		return false, nil
+	case len(platform) > 0 && len(platformStatusType) > 0 && platform != platformStatusType:
+		return false, field.Invalid(statusPath.Child("platformStatus", "type"), platformStatusType,
+			fmt.Sprintf("does not match %s %q", statusPath.Child("platform"), platform))
+	}
+
+	if specType := infra.Spec.PlatformSpec.Type; len(specType) > 0 {
+		if statusType := firstNonEmpty(platformStatusType, platform); specType != statusType {
+			return false, field.Invalid(field.NewPath("spec", "platformSpec", "type"), specType,
+				fmt.Sprintf("does not match %s %q", statusPath.Child("platformStatus", "type"), statusType))
+		}
+	}
+
+	changed := false
+	if len(platformStatusType) == 0 {
+		if infra.Status.PlatformStatus == nil {
+			infra.Status.PlatformStatus = &configv1.PlatformStatus{}
+		}
+		infra.Status.PlatformStatus.Type = platform
+		changed = true
+	}
+	if len(platform) == 0 {
+		infra.Status.Platform = platformStatusType
+		changed = true
+	}
+	return changed, nil
+}
+
+func firstNonEmpty(values ...configv1.PlatformType) configv1.PlatformType {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package infrastructure_normalizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfakeclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func Test_sync(t *testing.T) {
+	cases := []struct {
+		name        string
+		inputspec   configv1.InfrastructureSpec
+		inputstatus configv1.InfrastructureStatus
+
+		outputstatus *configv1.InfrastructureStatus
+		err          string
+	}{{
+		name:        "empty",
+		inputstatus: configv1.InfrastructureStatus{},
+	}, {
+		name:         "platform status type from platform",
+		inputstatus:  configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType},
+		outputstatus: &configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType}},
+	}, {
+		name:         "platform from platform status type",
+		inputstatus:  configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType, GCP: &configv1.GCPPlatformStatus{Region: "us-east1"}}},
+		outputstatus: &configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType, GCP: &configv1.GCPPlatformStatus{Region: "us-east1"}}},
+	}, {
+		name:        "consistent",
+		inputspec:   configv1.InfrastructureSpec{PlatformSpec: configv1.PlatformSpec{Type: configv1.AWSPlatformType}},
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
+	}, {
+		name:        "status mismatch",
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.PlatformType("oldType"), PlatformStatus: &configv1.PlatformStatus{Type: "newType"}},
+		err:         `^status\.platformStatus\.type: Invalid value: "newType": does not match status\.platform "oldType"$`,
+	}, {
+		name:        "spec mismatch",
+		inputspec:   configv1.InfrastructureSpec{PlatformSpec: configv1.PlatformSpec{Type: configv1.GCPPlatformType}},
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		err:         `^spec\.platformSpec\.type: Invalid value: "GCP": does not match status\.platformStatus\.type "AWS"$`,
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Spec: test.inputspec, Status: test.inputstatus}
+			indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := indexerInfra.Add(infra); err != nil {
+				t.Fatal(err.Error())
+			}
+			fakeConfig := configfakeclient.NewClientset(infra)
+
+			ctrl := InfrastructureNormalizerController{
+				infraClient: fakeConfig.ConfigV1().Infrastructures(),
+				infraLister: configv1listers.NewInfrastructureLister(indexerInfra),
+			}
+			err := ctrl.sync(context.TODO(),
+				factory.NewSyncContext("InfrastructureNormalizerController", events.NewInMemoryRecorder("InfrastructureNormalizerController", clocktesting.NewFakePassiveClock(time.Now()))))
+			if test.err != "" {
+				assert.Regexp(t, test.err, err)
+				assert.Empty(t, fakeConfig.Actions())
+				return
+			}
+			assert.NoError(t, err)
+			if test.outputstatus == nil {
+				assert.Empty(t, fakeConfig.Actions())
+				return
+			}
+			for _, a := range fakeConfig.Actions() {
+				patch := a.(ktesting.PatchAction)
+				assert.Equal(t, types.ApplyPatchType, patch.GetPatchType())
+				assert.Equal(t, "status", patch.GetSubresource())
+			}
+			got, err := fakeConfig.ConfigV1().Infrastructures().Get(context.TODO(), "cluster", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, *test.outputstatus, got.Status)
+		})
+	}
+}
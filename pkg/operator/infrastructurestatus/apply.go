@@ -0,0 +1,71 @@
+// Package infrastructurestatus writes the status of the Infrastructure with server-side apply, so that each controller
+// owns only the fields it sets and conflicts with other field managers are reported instead of overwritten.
+package infrastructurestatus
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	configv1 "github.com/openshift/api/config/v1"
+	applyconfigv1 "github.com/openshift/client-go/config/applyconfigurations/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// legacyFieldManagers own fields that were written before the controllers moved to server-side apply: the field
+// manager of the UpdateStatus calls this operator used to make, the placeholder the API server uses for fields
+// written before managed fields were tracked, and the managers of the bootstrap components that create the
+// Infrastructure and its status from the installer manifests. Fields they own are taken over instead of being reported
+// as conflicts.
+var legacyFieldManagers = sets.New("cluster-config-operator", "before-first-apply", "cluster-bootstrap", "kube-apiserver")
+
+var conflictManagerRE = regexp.MustCompile(`^conflict with "([^"]*)"`)
+
+// Apply server-side applies status to the status subresource of infrastructures.config.openshift.io/cluster as
+// fieldManager. The status must only contain the fields owned by fieldManager, so that controllers writing
+// different parts of the status do not overwrite each other.
+//
+// Conflicts with other field managers are not forced, but reported as a warning event and returned. The only
+// exception are conflicts solely with legacyFieldManagers, which are forced.
+func Apply(ctx context.Context, client configv1client.InfrastructureInterface, recorder events.Recorder, fieldManager string, status *applyconfigv1.InfrastructureStatusApplyConfiguration) error {
+	infra := applyconfigv1.Infrastructure("cluster").WithStatus(status)
+	_, err := client.ApplyStatus(ctx, infra, metav1.ApplyOptions{FieldManager: fieldManager})
+	if !apierrors.IsConflict(err) {
+		return err
+	}
+
+	managers := conflictingManagers(err)
+	if len(managers) > 0 && legacyFieldManagers.HasAll(managers...) {
+		_, err = client.ApplyStatus(ctx, infra, metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+		return err
+	}
+
+	recorder.Warningf("InfrastructureStatusConflict", "%s did not update infrastructures.%s/cluster status: %v", fieldManager, configv1.GroupName, err)
+	return fmt.Errorf("unable to apply infrastructures.%s/cluster status as %s: %w", configv1.GroupName, fieldManager, err)
+}
+
+// conflictingManagers returns the unique field managers named by the causes of an apply conflict error.
+func conflictingManagers(err error) []string {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+	var managers []string
+	seen := map[string]bool{}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		m := conflictManagerRE.FindStringSubmatch(cause.Message)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		managers = append(managers, m[1])
+	}
+	return managers
+}
@@ -0,0 +1,106 @@
+package infrastructurestatus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	applyconfigv1 "github.com/openshift/client-go/config/applyconfigurations/config/v1"
+	configfakeclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func regionStatus(region string) *applyconfigv1.InfrastructureStatusApplyConfiguration {
+	return applyconfigv1.InfrastructureStatus().WithPlatformStatus(applyconfigv1.PlatformStatus().
+		WithAWS(applyconfigv1.AWSPlatformStatus().WithRegion(region)))
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name  string
+		owner string
+		// updated makes the owner write the field with an update instead of an apply, like the bootstrap components
+		updated       bool
+		expectedErr   string
+		expectedEvent bool
+		region        string
+	}{{
+		name:   "no owner",
+		region: "us-west-2",
+	}, {
+		name:   "owned by the same manager",
+		owner:  "AWSPlatformServiceLocationController",
+		region: "us-west-2",
+	}, {
+		name:    "owned by the legacy operator manager",
+		owner:   "cluster-config-operator",
+		updated: true,
+		region:  "us-west-2",
+	}, {
+		name:    "owned by cluster-bootstrap",
+		owner:   "cluster-bootstrap",
+		updated: true,
+		region:  "us-west-2",
+	}, {
+		name:    "owned by kube-apiserver",
+		owner:   "kube-apiserver",
+		updated: true,
+		region:  "us-west-2",
+	}, {
+		name:          "updated by another manager",
+		owner:         "installer",
+		updated:       true,
+		expectedErr:   `unable to apply infrastructures.config.openshift.io/cluster status as AWSPlatformServiceLocationController: .*conflict with "installer"`,
+		expectedEvent: true,
+		region:        "us-east-1",
+	}, {
+		name:          "owned by another manager",
+		owner:         "installer",
+		expectedErr:   `unable to apply infrastructures.config.openshift.io/cluster status as AWSPlatformServiceLocationController: .*conflict with "installer"`,
+		expectedEvent: true,
+		region:        "us-east-1",
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := configfakeclient.NewClientset(&configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}})
+			switch {
+			case tc.updated:
+				infra, err := client.ConfigV1().Infrastructures().Get(context.TODO(), "cluster", metav1.GetOptions{})
+				require.NoError(t, err)
+				infra.Status.PlatformStatus = &configv1.PlatformStatus{AWS: &configv1.AWSPlatformStatus{Region: "us-east-1"}}
+				_, err = client.ConfigV1().Infrastructures().UpdateStatus(context.TODO(), infra, metav1.UpdateOptions{FieldManager: tc.owner})
+				require.NoError(t, err)
+			case tc.owner != "":
+				_, err := client.ConfigV1().Infrastructures().ApplyStatus(context.TODO(),
+					applyconfigv1.Infrastructure("cluster").WithStatus(regionStatus("us-east-1")),
+					metav1.ApplyOptions{FieldManager: tc.owner})
+				require.NoError(t, err)
+			}
+			recorder := events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+			err := Apply(context.TODO(), client.ConfigV1().Infrastructures(), recorder, "AWSPlatformServiceLocationController", regionStatus("us-west-2"))
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Regexp(t, tc.expectedErr, err.Error())
+			}
+
+			var conflictEvents int
+			for _, e := range recorder.Events() {
+				if e.Reason == "InfrastructureStatusConflict" {
+					conflictEvents++
+				}
+			}
+			assert.Equal(t, tc.expectedEvent, conflictEvents == 1)
+
+			got, err := client.ConfigV1().Infrastructures().Get(context.TODO(), "cluster", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.region, got.Status.PlatformStatus.AWS.Region)
+		})
+	}
+}
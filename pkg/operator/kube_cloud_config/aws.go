@@ -3,40 +3,94 @@ package kubecloudconfig
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"strings"
 	"text/template"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-config-operator/pkg/operator/kube_cloud_config/internal/aws"
 	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"gopkg.in/gcfg.v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
-// awsTransformer implements the cloudConfigTransformer. It uses the input ConfigMap and infra.status.platformStatus.AWS.ServiceEndpoints
-// to create a new config that include the ServiceOverrides sections. The transformer uses infra.status.platformStatus.region as the
-// signing service for all the ServiceOverrides.
+func init() {
+	RegisterTransformer(awsTransformer{})
+}
+
+// awsTransformer implements the Transformer for AWS.
+type awsTransformer struct{}
+
+func (awsTransformer) Platform() configv1.PlatformType { return configv1.AWSPlatformType }
+
+func (awsTransformer) Owns(featuregates.FeatureGateAccess) bool { return true }
+
+// Validate requires the AWS platform status to have a region when service endpoints are set, and a supported IP family.
 // It returns an error if the platform is not AWSPlatformType.
-func awsTransformer(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
+func (awsTransformer) Validate(infra *configv1.Infrastructure) error {
 	if !(infra.Status.PlatformStatus != nil &&
 		infra.Status.PlatformStatus.Type == configv1.AWSPlatformType) {
-		return nil, fmt.Errorf("invalid platform, expected to be AWS")
+		return fmt.Errorf("invalid platform, expected to be AWS")
 	}
-	if infra.Status.PlatformStatus.AWS == nil || len(infra.Status.PlatformStatus.AWS.ServiceEndpoints) == 0 {
+	awsPlatform := infra.Status.PlatformStatus.AWS
+	if awsPlatform == nil {
+		return nil
+	}
+	if len(awsPlatform.ServiceEndpoints) > 0 && awsPlatform.Region == "" {
+		return field.Required(field.NewPath("status", "platformStatus", "aws", "region"), "region is required to be set for AWS platform")
+	}
+	if _, err := nodeIPFamilies(awsPlatform.IPFamily, field.NewPath("status", "platformStatus", "aws", "ipFamily")); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EqualConfigs returns true if a and b hold the same AWS cloud provider settings. Configs that cannot be read are only
+// equal to themselves.
+func (awsTransformer) EqualConfigs(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var cfgA, cfgB aws.CloudConfig
+	if err := gcfg.ReadInto(&cfgA, bytes.NewReader(a)); err != nil {
+		return false
+	}
+	if err := gcfg.ReadInto(&cfgB, bytes.NewReader(b)); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(cfgA, cfgB)
+}
+
+// Transform uses the input ConfigMap and infra.status.platformStatus.AWS.ServiceEndpoints
+// to create a new config that include the ServiceOverrides sections. The transformer uses infra.status.platformStatus.region as the
+// signing service for all the ServiceOverrides. The NodeIPFamilies are added to the existing [Global] section from
+// infra.status.platformStatus.AWS.IPFamily, unless the input config already sets matching ones. IPv4 is the default of
+// the cloud provider and is not written.
+// The input config is copied byte-for-byte when there is nothing to add to it.
+func (awsTransformer) Transform(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
+	awsPlatform := infra.Status.PlatformStatus.AWS
+	if awsPlatform == nil {
 		return asIsTransformer(input, key, infra) // no transformation required
 	}
 
-	var region string
-	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.AWS != nil {
-		region = infra.Status.PlatformStatus.AWS.Region
+	ipFamilyPath := field.NewPath("status", "platformStatus", "aws", "ipFamily")
+	families, err := nodeIPFamilies(awsPlatform.IPFamily, ipFamilyPath)
+	if err != nil {
+		return nil, err
 	}
-	if region == "" {
-		return nil, field.Required(field.NewPath("status", "platformStatus", "aws", "region"), "region is required to be set for AWS platform")
+	if equalIPFamilies(families, defaultNodeIPFamilies) {
+		families = nil
 	}
+	if len(awsPlatform.ServiceEndpoints) == 0 && len(families) == 0 {
+		return asIsTransformer(input, key, infra) // no transformation required
+	}
+	region := awsPlatform.Region
 
 	output := input.DeepCopy()
 	output.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
-	output.Name = targetConfigName
+	output.Name = TargetConfigName
 	delete(output.Data, key)
 	delete(output.BinaryData, key)
 
@@ -47,26 +101,39 @@ func awsTransformer(input *corev1.ConfigMap, key string, infra *configv1.Infrast
 		inCfgRaw = bytes.NewBuffer(v)
 	}
 
+	var cfg aws.CloudConfig
 	if len(inCfgRaw.String()) > 0 {
-		var cfg aws.CloudConfig
 		err := gcfg.ReadInto(&cfg, bytes.NewBufferString(inCfgRaw.String()))
 		if err != nil {
 			return nil, fmt.Errorf("failed to read the cloud.conf: %w", err)
 		}
 
-		if len(cfg.ServiceOverride) > 0 {
+		if len(awsPlatform.ServiceEndpoints) > 0 && len(cfg.ServiceOverride) > 0 {
 			return nil, fmt.Errorf("invalid user provided cloud.conf: user provided cloud.conf and infrastructure object both include service overrides")
 		}
 	}
 
-	overrides, err := serviceOverrides(infra.Status.PlatformStatus.AWS.ServiceEndpoints, region)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create service overrides section for cloud.conf: %w", err)
+	if len(families) > 0 {
+		if len(cfg.Global.NodeIPFamilies) > 0 {
+			if !equalIPFamilies(cfg.Global.NodeIPFamilies, families) {
+				return nil, field.Invalid(ipFamilyPath, awsPlatform.IPFamily,
+					fmt.Sprintf("conflicts with NodeIPFamilies %v in user-provided cloud.conf", cfg.Global.NodeIPFamilies))
+			}
+		} else {
+			inCfgRaw = bytes.NewBufferString(withNodeIPFamilies(inCfgRaw.String(), families))
+		}
 	}
 
-	_, err = inCfgRaw.WriteString(overrides)
-	if err != nil {
-		return nil, fmt.Errorf("failed to append service overrides section for cloud.conf: %w", err)
+	if len(awsPlatform.ServiceEndpoints) > 0 {
+		overrides, err := serviceOverrides(awsPlatform.ServiceEndpoints, region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create service overrides section for cloud.conf: %w", err)
+		}
+
+		_, err = inCfgRaw.WriteString(overrides)
+		if err != nil {
+			return nil, fmt.Errorf("failed to append service overrides section for cloud.conf: %w", err)
+		}
 	}
 
 	if _, ok := input.Data[key]; ok {
@@ -83,6 +150,56 @@ func awsTransformer(input *corev1.ConfigMap, key string, infra *configv1.Infrast
 	return output, nil
 }
 
+// withNodeIPFamilies returns cfg with NodeIPFamilies set in the order given. They are added to the first [Global]
+// section of cfg, or to a new [Global] section at its end if it has none.
+func withNodeIPFamilies(cfg string, families []string) string {
+	lines := &strings.Builder{}
+	for _, family := range families {
+		fmt.Fprintf(lines, "\tNodeIPFamilies = %s\n", family)
+	}
+
+	offset := 0
+	for offset < len(cfg) {
+		end := strings.IndexByte(cfg[offset:], '\n')
+		if end < 0 {
+			end = len(cfg)
+		} else {
+			end += offset + 1
+		}
+		if isGlobalSectionHeader(cfg[offset:end]) {
+			header := cfg[:end]
+			if !strings.HasSuffix(header, "\n") {
+				header += "\n"
+			}
+			return header + lines.String() + cfg[end:]
+		}
+		offset = end
+	}
+
+	if len(cfg) > 0 && !strings.HasSuffix(cfg, "\n") {
+		cfg += "\n"
+	}
+	return cfg + "\n[Global]\n" + lines.String()
+}
+
+// isGlobalSectionHeader returns true if line is the header of the [Global] section. Like gcfg, it matches the section
+// name case-insensitively and allows a trailing comment.
+func isGlobalSectionHeader(line string) bool {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") {
+		return false
+	}
+	end := strings.IndexByte(line, ']')
+	if end < 0 {
+		return false
+	}
+	rest := strings.TrimSpace(line[end+1:])
+	if len(rest) > 0 && rest[0] != ';' && rest[0] != '#' {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(line[1:end]), "global")
+}
+
 // serviceOverrides returns a section of configuration that matches the expected based on https://github.com/kubernetes/kubernetes/blob/46b2891089574749b3d98b2a09fc3270789795b6/staging/src/k8s.io/legacy-cloud-providers/aws/aws.go#L595-L607
 // since there is no writer for gopkg.in/gcfg.v1 available, we have to manually create a section block that is compatible with gcfg.
 func serviceOverrides(overrides []configv1.AWSServiceEndpoint, defaultRegion string) (string, error) {
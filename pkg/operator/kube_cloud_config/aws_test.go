@@ -416,11 +416,104 @@ NodeIPFamilies = ipv4
 	SigningRegion = test-region
 `}},
 		err: ``,
+	}, {
+		name:       "empty config map, aws infra with dual-stack",
+		inputcm:    &corev1.ConfigMap{},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{IPFamily: configv1.DualStackIPv6Primary}}}},
+
+		outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `
+[Global]
+	NodeIPFamilies = ipv6
+	NodeIPFamilies = ipv4
+`}},
+		err: ``,
+	}, {
+		name: "config map, aws infra with dual-stack and service endpoints",
+		inputcm: &corev1.ConfigMap{Data: map[string]string{"config": `[Global]
+VPC = vpc-test
+`}},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "test-region", ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "ec2.local"}}, IPFamily: configv1.DualStackIPv4Primary}}}},
+
+		outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `[Global]
+	NodeIPFamilies = ipv4
+	NodeIPFamilies = ipv6
+VPC = vpc-test
+
+[ServiceOverride "0"]
+	Service = ec2
+	Region = test-region
+	URL = ec2.local
+	SigningRegion = test-region
+`}},
+		err: ``,
+	}, {
+		name: "config map with commented global section, aws infra with dual-stack",
+		inputcm: &corev1.ConfigMap{Data: map[string]string{"config": `# cluster settings
+[ global ] ; the global section
+VPC = vpc-test
+`}},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{IPFamily: configv1.DualStackIPv6Primary}}}},
+
+		outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `# cluster settings
+[ global ] ; the global section
+	NodeIPFamilies = ipv6
+	NodeIPFamilies = ipv4
+VPC = vpc-test
+`}},
+		err: ``,
+	}, {
+		name: "config map without global section, aws infra with dual-stack",
+		inputcm: &corev1.ConfigMap{Data: map[string]string{"config": `[ServiceOverride "0"]
+Service = ec2
+Region = test-region
+URL = ec2.local
+SigningRegion = test-region`}},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{IPFamily: configv1.DualStackIPv4Primary}}}},
+
+		outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `[ServiceOverride "0"]
+Service = ec2
+Region = test-region
+URL = ec2.local
+SigningRegion = test-region
+
+[Global]
+	NodeIPFamilies = ipv4
+	NodeIPFamilies = ipv6
+`}},
+		err: ``,
+	}, {
+		name: "config map with matching node IP families, aws infra with IPv4",
+		inputcm: &corev1.ConfigMap{Data: map[string]string{"config": `[Global]
+NodeIPFamilies = IPv4
+`}},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{IPFamily: configv1.IPv4}}}},
+
+		outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `[Global]
+NodeIPFamilies = IPv4
+`}},
+		err: ``,
+	}, {
+		name: "config map with conflicting node IP families, aws infra with dual-stack",
+		inputcm: &corev1.ConfigMap{Data: map[string]string{"config": `[Global]
+NodeIPFamilies = ipv4
+NodeIPFamilies = ipv6
+`}},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{IPFamily: configv1.DualStackIPv6Primary}}}},
+
+		outputcm: nil,
+		err:      `status\.platformStatus\.aws\.ipFamily: Invalid value: "DualStackIPv6Primary": conflicts with NodeIPFamilies \[ipv4 ipv6\] in user-provided cloud.conf`,
+	}, {
+		name:       "empty config map, aws infra with invalid ip family",
+		inputcm:    &corev1.ConfigMap{},
+		inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{IPFamily: "IPv6"}}}},
+
+		outputcm: nil,
+		err:      `status\.platformStatus\.aws\.ipFamily: Unsupported value: "IPv6"`,
 	}}
 
 	for _, test := range cases {
 		t.Run(test.name, func(t *testing.T) {
-			outputcm, err := awsTransformer(test.inputcm, "config", test.inputinfra)
+			outputcm, err := transform(awsTransformer{}, test.inputcm, "config", test.inputinfra)
 			if test.err == "" {
 				assert.NoError(t, err)
 				outputcm.ObjectMeta = metav1.ObjectMeta{}
@@ -431,3 +524,38 @@ NodeIPFamilies = ipv4
 		})
 	}
 }
+
+func Test_awsTransformer_EqualConfigs(t *testing.T) {
+	cases := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{{
+		name:  "reordered keys and whitespace",
+		a:     "[Global]\nVPC = vpc-test\nSubnetID = subnet-test\n",
+		b:     "[global]\n\tsubnetid   = subnet-test\n\n\tVPC = vpc-test ; the VPC\n",
+		equal: true,
+	}, {
+		name:  "reordered sections",
+		a:     "[Global]\nVPC = vpc-test\n\n[ServiceOverride \"0\"]\nService = ec2\nRegion = test-region\nURL = https://ec2.local\nSigningRegion = test-region\n",
+		b:     "[ServiceOverride \"0\"]\nURL = https://ec2.local\nService = ec2\nSigningRegion = test-region\nRegion = test-region\n\n[Global]\nVPC = vpc-test\n",
+		equal: true,
+	}, {
+		name: "different value",
+		a:    "[Global]\nVPC = vpc-test\n",
+		b:    "[Global]\nVPC = vpc-other\n",
+	}, {
+		name: "different order of node IP families",
+		a:    "[Global]\nNodeIPFamilies = ipv4\nNodeIPFamilies = ipv6\n",
+		b:    "[Global]\nNodeIPFamilies = ipv6\nNodeIPFamilies = ipv4\n",
+	}, {
+		name: "unreadable",
+		a:    "[Global]\nVPC = vpc-test\n",
+		b:    "VPC = vpc-test\n",
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.equal, awsTransformer{}.EqualConfigs([]byte(test.a), []byte(test.b)))
+		})
+	}
+}
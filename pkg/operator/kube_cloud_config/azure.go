@@ -2,8 +2,8 @@ package kubecloudconfig
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,6 +11,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 
 	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
 )
@@ -37,28 +38,111 @@ var (
 	}()
 )
 
-// azureTransformer implements the cloudConfigTransformer. It uses the input ConfigMap and infra.status.platformStatus.azure.cloudName
-// to create a new config that has the cloud field set.
-// It returns an error if the platform is not AzurePlatformType.
-func azureTransformer(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
+// azureStatusField is a cloud.conf field that is defaulted from infra.status.platformStatus.azure.
+type azureStatusField struct {
+	key   string
+	path  *field.Path
+	value string
+	// equal reports whether a user-provided value matches value.
+	equal func(a, b string) bool
+}
+
+// azureStatusFields returns the cloud.conf fields with a value in the Azure platform status.
+func azureStatusFields(status *configv1.AzurePlatformStatus) []azureStatusField {
+	if status == nil {
+		return nil
+	}
+	fldPath := field.NewPath("status", "platformStatus", "azure")
+	// resource group names are case-insensitive in Azure
+	fields := []azureStatusField{
+		{key: "resourceGroup", path: fldPath.Child("resourceGroupName"), value: status.ResourceGroupName, equal: strings.EqualFold},
+		{key: "vnetResourceGroup", path: fldPath.Child("networkResourceGroupName"), value: status.NetworkResourceGroupName, equal: strings.EqualFold},
+		{key: "resourceManagerEndpoint", path: fldPath.Child("armEndpoint"), value: status.ARMEndpoint, equal: equalEndpoints},
+	}
+	var set []azureStatusField
+	for _, f := range fields {
+		if f.value != "" {
+			set = append(set, f)
+		}
+	}
+	return set
+}
+
+func equalEndpoints(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "/"), strings.TrimSuffix(b, "/"))
+}
+
+func init() {
+	RegisterTransformer(azureTransformer{})
+}
+
+// azureTransformer implements the Transformer for Azure.
+type azureTransformer struct{}
+
+func (azureTransformer) Platform() configv1.PlatformType { return configv1.AzurePlatformType }
+
+func (azureTransformer) Owns(featuregates.FeatureGateAccess) bool { return true }
+
+// Validate requires the Azure platform status to have a supported cloud name, AzureStackCloud to have the ARM endpoint
+// set, and the IPv4 IP family: the Azure cloud provider config has no setting for the node IP families, so dual-stack
+// cannot be configured. It returns an error if the platform is not AzurePlatformType.
+func (azureTransformer) Validate(infra *configv1.Infrastructure) error {
 	if !(infra.Status.PlatformStatus != nil &&
 		infra.Status.PlatformStatus.Type == configv1.AzurePlatformType) {
-		return nil, fmt.Errorf("invalid platform, expected to be Azure")
+		return fmt.Errorf("invalid platform, expected to be Azure")
+	}
+	azurePlatform := infra.Status.PlatformStatus.Azure
+	if azurePlatform == nil {
+		return nil
+	}
+	if c := azurePlatform.CloudName; c != "" && !validAzureCloudNames[c] {
+		return field.NotSupported(field.NewPath("status", "platformStatus", "azure", "cloudName"), c, validAzureCloudNameValues)
+	}
+	if azurePlatform.CloudName == configv1.AzureStackCloud && azurePlatform.ARMEndpoint == "" {
+		return field.Required(field.NewPath("status", "platformStatus", "azure", "armEndpoint"), "the ARM endpoint is required for AzureStackCloud")
+	}
+	ipFamilyPath := field.NewPath("status", "platformStatus", "azure", "ipFamily")
+	families, err := nodeIPFamilies(azurePlatform.IPFamily, ipFamilyPath)
+	if err != nil {
+		return err
+	}
+	if len(families) > 0 && !equalIPFamilies(families, defaultNodeIPFamilies) {
+		return field.Invalid(ipFamilyPath, azurePlatform.IPFamily, "the Azure cloud provider config cannot set the node IP families, only IPv4 is supported")
 	}
+	return nil
+}
+
+// EqualConfigs returns true if a and b hold the same JSON document, regardless of the order of the keys and of
+// whitespace. Configs that cannot be read are only equal to themselves.
+func (azureTransformer) EqualConfigs(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var cfgA, cfgB interface{}
+	if err := yaml.Unmarshal(a, &cfgA); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal(b, &cfgB); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(cfgA, cfgB)
+}
 
+// Transform uses the input ConfigMap and infra.status.platformStatus.azure
+// to create a new config that has the cloud, resourceGroup, vnetResourceGroup and resourceManagerEndpoint fields set.
+// Fields already set in the input must match the infrastructure object. For AzureStackCloud, the ARM endpoint
+// is used to generate the Azure environment file stored at the endpoints key.
+// The input config is copied as-is when all fields are already set.
+func (azureTransformer) Transform(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
 	cloud := configv1.AzurePublicCloud
-	if azurePlatform := infra.Status.PlatformStatus.Azure; azurePlatform != nil {
-		if c := azurePlatform.CloudName; c != "" {
-			if !validAzureCloudNames[c] {
-				return nil, field.NotSupported(field.NewPath("status", "platformStatus", "azure", "cloudName"), c, validAzureCloudNameValues)
-			}
-			cloud = c
-		}
+	azurePlatform := infra.Status.PlatformStatus.Azure
+	if azurePlatform != nil && azurePlatform.CloudName != "" {
+		cloud = azurePlatform.CloudName
 	}
 
 	output := input.DeepCopy()
 	output.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
-	output.Name = targetConfigName
+	output.Name = TargetConfigName
 	delete(output.Data, key)
 	delete(output.BinaryData, key)
 
@@ -69,40 +153,51 @@ func azureTransformer(input *corev1.ConfigMap, key string, infra *configv1.Infra
 		inCfgRaw = v
 	}
 
-	useInCfg := false
-
 	var cfg map[string]interface{}
 	if len(inCfgRaw) > 0 {
 		if err := yaml.Unmarshal(inCfgRaw, &cfg); err != nil {
 			return nil, fmt.Errorf("failed to read the cloud.conf: %w", err)
 		}
-		if inCloudUntyped, ok := cfg[azureCloudFieldName]; ok {
-			inCloud, ok := inCloudUntyped.(string)
-			if !ok {
-				return nil, fmt.Errorf("invalid user-provided cloud.conf: \"cloud\" field is not a string")
-			}
-			if len(inCloud) > 0 {
-				if !strings.EqualFold(inCloud, string(cloud)) {
-					return nil, fmt.Errorf("invalid user-provided cloud.conf: \"cloud\" field in user-provided cloud.conf conflicts with infrastructure object")
-				}
-				useInCfg = true
+	}
+	if cfg == nil {
+		cfg = make(map[string]interface{}, 1)
+	}
+
+	modified := false
+	inCloud, err := stringField(cfg, azureCloudFieldName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user-provided cloud.conf: %w", err)
+	}
+	if len(inCloud) > 0 {
+		if !strings.EqualFold(inCloud, string(cloud)) {
+			return nil, fmt.Errorf("invalid user-provided cloud.conf: \"cloud\" field in user-provided cloud.conf conflicts with infrastructure object")
+		}
+	} else {
+		cfg[azureCloudFieldName] = string(cloud)
+		modified = true
+	}
+
+	for _, f := range azureStatusFields(azurePlatform) {
+		inValue, err := stringField(cfg, f.key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user-provided cloud.conf: %w", err)
+		}
+		if len(inValue) > 0 {
+			if !f.equal(inValue, f.value) {
+				return nil, field.Invalid(f.path, f.value, fmt.Sprintf("conflicts with %q field %q in user-provided cloud.conf", f.key, inValue))
 			}
+			continue
 		}
+		cfg[f.key] = f.value
+		modified = true
 	}
 
 	outCfgRaw := inCfgRaw
-	if !useInCfg {
-		if cfg == nil {
-			cfg = make(map[string]interface{}, 1)
-		}
-		cfg[azureCloudFieldName] = string(cloud)
-		outCfgBuffer := &bytes.Buffer{}
-		encoder := json.NewEncoder(outCfgBuffer)
-		encoder.SetIndent("", "\t")
-		if err := encoder.Encode(cfg); err != nil {
+	if modified {
+		outCfgRaw, err = canonicalJSON(cfg)
+		if err != nil {
 			return nil, fmt.Errorf("failed to encode config: %w", err)
 		}
-		outCfgRaw = outCfgBuffer.Bytes()
 	}
 
 	if _, ok := input.Data[key]; ok {
@@ -116,5 +211,30 @@ func azureTransformer(input *corev1.ConfigMap, key string, infra *configv1.Infra
 		output.Data[targetConfigKey] = string(outCfgRaw) // store the new config to input key
 	}
 
+	if cloud == configv1.AzureStackCloud {
+		endpoints, err := azureStackEndpointsFile(input, azurePlatform.ARMEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		delete(output.BinaryData, azureStackEndpointsKey)
+		if output.Data == nil {
+			output.Data = make(map[string]string, 1)
+		}
+		output.Data[azureStackEndpointsKey] = string(endpoints)
+	}
+
 	return output, nil
 }
+
+// stringField returns the value of the named field of a user-provided JSON document, or an empty string if it is unset.
+func stringField(cfg map[string]interface{}, name string) (string, error) {
+	untyped, ok := cfg[name]
+	if !ok {
+		return "", nil
+	}
+	value, ok := untyped.(string)
+	if !ok {
+		return "", fmt.Errorf("%q field is not a string", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package kubecloudconfig
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// azureStackEndpointsKey is the key of the Azure environment file used by the Azure cloud provider on Azure Stack Hub.
+	azureStackEndpointsKey = "endpoints"
+)
+
+// azureStackEndpoints returns the fields of the Azure environment file that can be derived from the ARM endpoint of an
+// Azure Stack Hub, which is of the form https://management.<region>.<fqdn>.
+func azureStackEndpoints(armEndpoint string) (map[string]string, error) {
+	fldPath := field.NewPath("status", "platformStatus", "azure", "armEndpoint")
+	u, err := url.Parse(armEndpoint)
+	if err != nil {
+		return nil, field.Invalid(fldPath, armEndpoint, err.Error())
+	}
+	domain, ok := strings.CutPrefix(strings.ToLower(u.Hostname()), "management.")
+	if u.Scheme != "https" || !ok || !strings.Contains(domain, ".") {
+		return nil, field.Invalid(fldPath, armEndpoint, "expected an Azure Stack Hub ARM endpoint of the form https://management.<region>.<fqdn>")
+	}
+	// the region is the first label of the external domain
+	fqdn := domain[strings.Index(domain, ".")+1:]
+
+	return map[string]string{
+		"name":                       string(configv1.AzureStackCloud),
+		"resourceManagerEndpoint":    fmt.Sprintf("https://%s/", u.Host),
+		"storageEndpointSuffix":      domain,
+		"keyVaultDNSSuffix":          "vault." + domain,
+		"resourceManagerVMDNSSuffix": "cloudapp." + fqdn,
+	}, nil
+}
+
+// azureStackEndpointsFile returns the Azure environment file for the Azure Stack Hub with the given ARM endpoint.
+// Fields that cannot be derived from the ARM endpoint, like the token audience or the active directory endpoint, are
+// taken from the endpoints key of the input ConfigMap, whose derivable fields must match the ARM endpoint.
+func azureStackEndpointsFile(input *corev1.ConfigMap, armEndpoint string) ([]byte, error) {
+	derived, err := azureStackEndpoints(armEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var inRaw []byte
+	if v, ok := input.Data[azureStackEndpointsKey]; ok {
+		inRaw = []byte(v)
+	} else if v, ok := input.BinaryData[azureStackEndpointsKey]; ok {
+		inRaw = v
+	}
+
+	var endpoints map[string]interface{}
+	if len(inRaw) > 0 {
+		if err := yaml.Unmarshal(inRaw, &endpoints); err != nil {
+			return nil, fmt.Errorf("failed to read the user-provided %s: %w", azureStackEndpointsKey, err)
+		}
+	}
+	if endpoints == nil {
+		endpoints = make(map[string]interface{}, len(derived))
+	}
+
+	for name, value := range derived {
+		inValue, err := stringField(endpoints, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user-provided %s: %w", azureStackEndpointsKey, err)
+		}
+		if len(inValue) > 0 {
+			if !equalEndpoints(inValue, value) {
+				return nil, field.Invalid(field.NewPath("status", "platformStatus", "azure", "armEndpoint"), armEndpoint,
+					fmt.Sprintf("conflicts with %q field %q in user-provided %s", name, inValue, azureStackEndpointsKey))
+			}
+			continue
+		}
+		endpoints[name] = value
+	}
+
+	out, err := canonicalJSON(endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", azureStackEndpointsKey, err)
+	}
+	return out, nil
+}
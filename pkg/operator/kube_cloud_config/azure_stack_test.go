@@ -0,0 +1,82 @@
+package kubecloudconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_azureStackEndpointsFile(t *testing.T) {
+	cases := []struct {
+		name        string
+		inputcm     *corev1.ConfigMap
+		armEndpoint string
+
+		output string
+		err    string
+	}{{
+		name:        "no user-provided endpoints",
+		inputcm:     &corev1.ConfigMap{},
+		armEndpoint: "https://management.ppe3.stackpoc.com",
+		output: `{
+	"keyVaultDNSSuffix": "vault.ppe3.stackpoc.com",
+	"name": "AzureStackCloud",
+	"resourceManagerEndpoint": "https://management.ppe3.stackpoc.com/",
+	"resourceManagerVMDNSSuffix": "cloudapp.stackpoc.com",
+	"storageEndpointSuffix": "ppe3.stackpoc.com"
+}
+`,
+	}, {
+		name: "user-provided endpoints are kept",
+		inputcm: &corev1.ConfigMap{BinaryData: map[string][]byte{"endpoints": []byte(`{
+	"activeDirectoryEndpoint": "https://login.microsoftonline.com/",
+	"resourceManagerEndpoint": "https://management.ppe3.stackpoc.com",
+	"tokenAudience": "https://management.stackpoc.onmicrosoft.com/3ee899c8-c137-4ec4-a0e1-0bd0e6a3c1f5"
+}`)}},
+		armEndpoint: "https://management.ppe3.stackpoc.com/",
+		output: `{
+	"activeDirectoryEndpoint": "https://login.microsoftonline.com/",
+	"keyVaultDNSSuffix": "vault.ppe3.stackpoc.com",
+	"name": "AzureStackCloud",
+	"resourceManagerEndpoint": "https://management.ppe3.stackpoc.com",
+	"resourceManagerVMDNSSuffix": "cloudapp.stackpoc.com",
+	"storageEndpointSuffix": "ppe3.stackpoc.com",
+	"tokenAudience": "https://management.stackpoc.onmicrosoft.com/3ee899c8-c137-4ec4-a0e1-0bd0e6a3c1f5"
+}
+`,
+	}, {
+		name:        "conflicting user-provided endpoints",
+		inputcm:     &corev1.ConfigMap{Data: map[string]string{"endpoints": `{"storageEndpointSuffix":"other.stackpoc.com"}`}},
+		armEndpoint: "https://management.ppe3.stackpoc.com",
+		err:         `status\.platformStatus\.azure\.armEndpoint: Invalid value: "https://management.ppe3.stackpoc.com": conflicts with "storageEndpointSuffix" field "other.stackpoc.com" in user-provided endpoints`,
+	}, {
+		name:        "invalid user-provided endpoints",
+		inputcm:     &corev1.ConfigMap{Data: map[string]string{"endpoints": `{"name":1}`}},
+		armEndpoint: "https://management.ppe3.stackpoc.com",
+		err:         `invalid user-provided endpoints: "name" field is not a string`,
+	}, {
+		name:        "not an Azure Stack Hub ARM endpoint",
+		inputcm:     &corev1.ConfigMap{},
+		armEndpoint: "https://arm.ppe3.stackpoc.com",
+		err:         `status\.platformStatus\.azure\.armEndpoint: Invalid value: "https://arm.ppe3.stackpoc.com": expected an Azure Stack Hub ARM endpoint`,
+	}, {
+		name:        "insecure ARM endpoint",
+		inputcm:     &corev1.ConfigMap{},
+		armEndpoint: "http://management.ppe3.stackpoc.com",
+		err:         `expected an Azure Stack Hub ARM endpoint`,
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := azureStackEndpointsFile(test.inputcm, test.armEndpoint)
+			if test.err == "" {
+				if assert.NoError(t, err) {
+					assert.Equal(t, test.output, string(output))
+				}
+			} else {
+				assert.Regexp(t, test.err, err)
+			}
+		})
+	}
+}
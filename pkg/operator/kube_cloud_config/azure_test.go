@@ -71,7 +71,8 @@ func Test_azureTransformer(t *testing.T) {
 			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{ResourceGroupName: "test-rg"}}}},
 
 			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{
-	"cloud": "AzurePublicCloud"
+	"cloud": "AzurePublicCloud",
+	"resourceGroup": "test-rg"
 }
 `}},
 			err: ``,
@@ -386,12 +387,122 @@ func Test_azureTransformer(t *testing.T) {
 
 			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{"cloud":"AZUREPUBLICCLOUD"}`}},
 			err:      ``,
+		}, {
+			name:       "config map with user settings, azure infra with resource groups",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"cloud":"AzurePublicCloud","useInstanceMetadata":true}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzurePublicCloud, ResourceGroupName: "test-rg", NetworkResourceGroupName: "test-network-rg"}}}},
+
+			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{
+	"cloud": "AzurePublicCloud",
+	"resourceGroup": "test-rg",
+	"useInstanceMetadata": true,
+	"vnetResourceGroup": "test-network-rg"
+}
+`}},
+			err: ``,
+		}, {
+			name:       "config map with matching fields, azure infra with resource groups",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"cloud":"AzurePublicCloud","resourceGroup":"TEST-RG","vnetResourceGroup":"test-network-rg"}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzurePublicCloud, ResourceGroupName: "test-rg", NetworkResourceGroupName: "test-network-rg"}}}},
+
+			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{"cloud":"AzurePublicCloud","resourceGroup":"TEST-RG","vnetResourceGroup":"test-network-rg"}`}},
+			err:      ``,
+		}, {
+			name:       "config map with conflicting resource group, azure infra",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"resourceGroup":"other-rg"}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{ResourceGroupName: "test-rg"}}}},
+
+			outputcm: nil,
+			err:      `status\.platformStatus\.azure\.resourceGroupName: Invalid value: "test-rg": conflicts with "resourceGroup" field "other-rg" in user-provided cloud.conf`,
+		}, {
+			name:       "config map with conflicting network resource group, azure infra",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"vnetResourceGroup":"other-rg"}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{NetworkResourceGroupName: "test-network-rg"}}}},
+
+			outputcm: nil,
+			err:      `status\.platformStatus\.azure\.networkResourceGroupName: Invalid value: "test-network-rg": conflicts with "vnetResourceGroup" field "other-rg" in user-provided cloud.conf`,
+		}, {
+			name:       "config map with non-string resource group, azure infra",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"resourceGroup":1}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{ResourceGroupName: "test-rg"}}}},
+
+			outputcm: nil,
+			err:      `invalid user-provided cloud.conf: "resourceGroup" field is not a string`,
+		}, {
+			name:       "empty config map, azure infra with stack cloud",
+			inputcm:    &corev1.ConfigMap{},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzureStackCloud, ARMEndpoint: "https://management.local.azurestack.external"}}}},
+
+			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{
+	"cloud": "AzureStackCloud",
+	"resourceManagerEndpoint": "https://management.local.azurestack.external"
+}
+`, "endpoints": `{
+	"keyVaultDNSSuffix": "vault.local.azurestack.external",
+	"name": "AzureStackCloud",
+	"resourceManagerEndpoint": "https://management.local.azurestack.external/",
+	"resourceManagerVMDNSSuffix": "cloudapp.azurestack.external",
+	"storageEndpointSuffix": "local.azurestack.external"
+}
+`}},
+			err: ``,
+		}, {
+			name:       "config map with matching endpoint, azure infra with stack cloud",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"cloud":"AzureStackCloud","resourceManagerEndpoint":"https://management.local.azurestack.external/"}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzureStackCloud, ARMEndpoint: "https://management.local.azurestack.external"}}}},
+
+			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{"cloud":"AzureStackCloud","resourceManagerEndpoint":"https://management.local.azurestack.external/"}`, "endpoints": `{
+	"keyVaultDNSSuffix": "vault.local.azurestack.external",
+	"name": "AzureStackCloud",
+	"resourceManagerEndpoint": "https://management.local.azurestack.external/",
+	"resourceManagerVMDNSSuffix": "cloudapp.azurestack.external",
+	"storageEndpointSuffix": "local.azurestack.external"
+}
+`}},
+			err: ``,
+		}, {
+			name:       "config map with conflicting endpoint, azure infra with stack cloud",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"resourceManagerEndpoint":"https://management.other.azurestack.external"}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzureStackCloud, ARMEndpoint: "https://management.local.azurestack.external"}}}},
+
+			outputcm: nil,
+			err:      `status\.platformStatus\.azure\.armEndpoint: Invalid value: "https://management.local.azurestack.external": conflicts with "resourceManagerEndpoint" field`,
+		}, {
+			name:       "config map with endpoint, azure infra with stack cloud without endpoint",
+			inputcm:    &corev1.ConfigMap{Data: map[string]string{"config": `{"resourceManagerEndpoint":"https://management.local.azurestack.external"}`}},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzureStackCloud}}}},
+
+			outputcm: nil,
+			err:      `status\.platformStatus\.azure\.armEndpoint: Required value: the ARM endpoint is required for AzureStackCloud`,
+		}, {
+			name:       "empty config map, azure infra with IPv4",
+			inputcm:    &corev1.ConfigMap{},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{IPFamily: configv1.IPv4}}}},
+
+			outputcm: &corev1.ConfigMap{Data: map[string]string{"cloud.conf": `{
+	"cloud": "AzurePublicCloud"
+}
+`}},
+		}, {
+			name:       "empty config map, azure infra with dual-stack",
+			inputcm:    &corev1.ConfigMap{},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{IPFamily: configv1.DualStackIPv4Primary}}}},
+
+			outputcm: nil,
+			err:      `status\.platformStatus\.azure\.ipFamily: Invalid value: "DualStackIPv4Primary": the Azure cloud provider config cannot set the node IP families, only IPv4 is supported`,
+		}, {
+			name:       "empty config map, azure infra with unsupported IP family",
+			inputcm:    &corev1.ConfigMap{},
+			inputinfra: &configv1.Infrastructure{Status: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{IPFamily: "IPv6"}}}},
+
+			outputcm: nil,
+			err:      `status\.platformStatus\.azure\.ipFamily: Unsupported value: "IPv6"`,
 		},
 	}
 
 	for _, test := range cases {
 		t.Run(test.name, func(t *testing.T) {
-			outputcm, err := azureTransformer(test.inputcm, "config", test.inputinfra)
+			outputcm, err := transform(azureTransformer{}, test.inputcm, "config", test.inputinfra)
 			if test.err == "" {
 				if assert.NoError(t, err) {
 					outputcm.ObjectMeta = metav1.ObjectMeta{}
@@ -403,3 +514,29 @@ func Test_azureTransformer(t *testing.T) {
 		})
 	}
 }
+
+func Test_azureTransformer_EqualConfigs(t *testing.T) {
+	cases := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{{
+		name:  "reordered keys and whitespace",
+		a:     "{\"cloud\": \"AzurePublicCloud\", \"resourceGroup\": \"rg\", \"vmType\": {\"a\": 1, \"b\": 2}}",
+		b:     "{\n\t\"resourceGroup\": \"rg\",\n\t\"vmType\": {\"b\": 2, \"a\": 1},\n\t\"cloud\": \"AzurePublicCloud\"\n}\n",
+		equal: true,
+	}, {
+		name: "different value",
+		a:    "{\"cloud\": \"AzurePublicCloud\"}",
+		b:    "{\"cloud\": \"AzureUSGovernmentCloud\"}",
+	}, {
+		name: "unreadable",
+		a:    "{\"cloud\": \"AzurePublicCloud\"}",
+		b:    "{\"cloud\": ",
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.equal, azureTransformer{}.EqualConfigs([]byte(test.a), []byte(test.b)))
+		})
+	}
+}
@@ -2,14 +2,17 @@ package kubecloudconfig
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	configv1 "github.com/openshift/api/config/v1"
 	operatorclient "github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-config-operator/pkg/util"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 )
 
 // ValidateFile verifies a file exists, has content, and is a regular file
@@ -27,63 +30,87 @@ func ValidateFile(path string) error {
 	return nil
 }
 
-// BootstrapTransform implements the cloudConfigTransformer during bootstrapping.
+// BootstrapTransform transforms the cloud config with the platform Transformer during bootstrapping.
 // It uses the input ConfigMap and Infrastructure provided by files on the bootstrap
 // host to create a new config that has the cloud field set.
+// The input files may be YAML (including multi-document streams), JSON or List objects;
+// exactly one Infrastructure and at most one ConfigMap are expected in their respective files.
 func BootstrapTransform(infrastructureFile string, cloudProviderFile string) ([]byte, error) {
 
 	// Read, parse, and save the infrastructure object
 	var clusterInfrastructure configv1.Infrastructure
-	fileData, err := ioutil.ReadFile(infrastructureFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read infrastructure file: %w", err)
-	}
-	err = yaml.Unmarshal(fileData, &clusterInfrastructure)
-	if err != nil {
-		return nil, fmt.Errorf("failed unmarshal infrastructure: %w", err)
+	if err := util.ReadManifestFile(infrastructureFile, configv1.GroupVersion.WithKind("Infrastructure"), &clusterInfrastructure); err != nil {
+		return nil, fmt.Errorf("failed to read infrastructure: %w", err)
 	}
 
 	// Read, parse, and save the user provided cloud configmap
 	var cloudProviderConfigInput corev1.ConfigMap
 	if len(cloudProviderFile) > 0 {
-		fileData, err = ioutil.ReadFile(cloudProviderFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read cloud provider file: %w", err)
-		}
-		err = yaml.Unmarshal(fileData, &cloudProviderConfigInput)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal cloud provider: %w", err)
+		if err := util.ReadManifestFile(cloudProviderFile, corev1.SchemeGroupVersion.WithKind("ConfigMap"), &cloudProviderConfigInput); err != nil {
+			return nil, fmt.Errorf("failed to read cloud provider config: %w", err)
 		}
 	}
 
-	// Determine the platform type
-	var platformName configv1.PlatformType
-	if pstatus := clusterInfrastructure.Status.PlatformStatus; pstatus != nil {
-		platformName = pstatus.Type
+	target, err := bootstrapTarget(&clusterInfrastructure, &cloudProviderConfigInput)
+	if err != nil {
+		return nil, err
+	}
+
+	targetCloudConfigMapData, err := yaml.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marhsal cloud config: %w", err)
 	}
-	if len(platformName) == 0 {
-		platformName = clusterInfrastructure.Status.Platform
+
+	return targetCloudConfigMapData, nil
+}
+
+// BootstrapConfigMap returns the kube-cloud-config ConfigMap the KubeCloudConfigController would
+// create for the given Infrastructure and user provided cloud config.
+// It returns nil if the controller would not manage, or would delete, the kube-cloud-config.
+// A nil featureGates is treated as if no feature gates are enabled.
+func BootstrapConfigMap(infra *configv1.Infrastructure, source *corev1.ConfigMap, featureGates featuregates.FeatureGateAccess) (*corev1.ConfigMap, error) {
+	target, _, err := DesiredConfigMap(infra, source, featureGates)
+	return target, err
+}
+
+// DesiredConfigMap returns the kube-cloud-config ConfigMap the KubeCloudConfigController converges to for
+// the given Infrastructure and user provided cloud config, and whether the controller manages the
+// kube-cloud-config for the platform at all. A nil ConfigMap for a managed platform means that the
+// kube-cloud-config should not exist.
+func DesiredConfigMap(infra *configv1.Infrastructure, source *corev1.ConfigMap, featureGates featuregates.FeatureGateAccess) (*corev1.ConfigMap, bool, error) {
+	if !TransformerFor(util.PlatformType(infra)).Owns(featureGates) {
+		return nil, false, nil
 	}
 
-	// Determine the platform specific transformer method to use
-	cloudConfigTransformers := cloudConfigTransformers()
-	cloudConfigTransformerFn, ok := cloudConfigTransformers[platformName]
-	if !ok {
-		cloudConfigTransformerFn = asIsTransformer
+	if source == nil {
+		source = &corev1.ConfigMap{}
 	}
-	target, err := cloudConfigTransformerFn(&cloudProviderConfigInput, clusterInfrastructure.Spec.CloudConfig.Key, &clusterInfrastructure)
+	target, err := bootstrapTarget(infra, source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to transform cloud config: %w", err)
+		return nil, true, err
 	}
+	if len(target.Data) == 0 && len(target.BinaryData) == 0 {
+		return nil, true, nil
+	}
+	return target, true, nil
+}
 
-	target.Name = targetConfigName
-	target.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
-	/* ApplyConfigMap() */
-
-	targetCloudConfigMapData, err := yaml.Marshal(target)
+// bootstrapTarget transforms the source ConfigMap with the Transformer of the platform of infra.
+func bootstrapTarget(infra *configv1.Infrastructure, source *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	input := source.DeepCopy()
+	input.ObjectMeta = metav1.ObjectMeta{}
+	target, err := transform(TransformerFor(util.PlatformType(infra)), input, infra.Spec.CloudConfig.Key, infra)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marhsal cloud config: %w", err)
+		return nil, fmt.Errorf("failed to transform cloud config: %w", err)
 	}
 
-	return targetCloudConfigMapData, nil
+	target.Name = TargetConfigName
+	target.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
+	propagateMetadata(source.ObjectMeta, &target.ObjectMeta)
+	setContentHash(target)
+	target.TypeMeta = metav1.TypeMeta{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+	}
+	return target, nil
 }
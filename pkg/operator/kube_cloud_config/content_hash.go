@@ -0,0 +1,96 @@
+package kubecloudconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ContentHashAnnotation records the hash of the data of the kube-cloud-config ConfigMap.
+	ContentHashAnnotation = "kube-cloud-config.config.openshift.io/content-hash"
+)
+
+// contentHash returns a hash of the data and binary data of cm, independent of the order of the keys.
+func contentHash(cm *corev1.ConfigMap) string {
+	h := sha256.New()
+	for _, k := range sortedKeys(cm.Data) {
+		fmt.Fprintf(h, "data/%q=%q\n", k, cm.Data[k])
+	}
+	for _, k := range sortedKeys(cm.BinaryData) {
+		fmt.Fprintf(h, "binaryData/%q=%q\n", k, cm.BinaryData[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// setContentHash records the content hash of cm in its ContentHashAnnotation.
+func setContentHash(cm *corev1.ConfigMap) {
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[ContentHashAnnotation] = contentHash(cm)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// equalContent returns true if a and b hold the same data and binary data, comparing the cloud.conf at targetConfigKey
+// with equalConfigs.
+func equalContent(a, b *corev1.ConfigMap, equalConfigs func(a, b []byte) bool) bool {
+	return equalData(stringData(a.Data), stringData(b.Data), equalConfigs) && equalData(a.BinaryData, b.BinaryData, equalConfigs)
+}
+
+// equalData returns true if a and b have the same keys with the same values, comparing the values at targetConfigKey
+// with equalConfigs.
+func equalData(a, b map[string][]byte, equalConfigs func(a, b []byte) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, va := range a {
+		vb, ok := b[k]
+		if !ok {
+			return false
+		}
+		equal := bytes.Equal
+		if k == targetConfigKey {
+			equal = equalConfigs
+		}
+		if !equal(va, vb) {
+			return false
+		}
+	}
+	return true
+}
+
+func stringData(data map[string]string) map[string][]byte {
+	if data == nil {
+		return nil
+	}
+	ret := make(map[string][]byte, len(data))
+	for k, v := range data {
+		ret[k] = []byte(v)
+	}
+	return ret
+}
+
+// canonicalJSON encodes v with sorted object keys and tab indentation.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	out := &bytes.Buffer{}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
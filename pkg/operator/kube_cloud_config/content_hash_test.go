@@ -0,0 +1,22 @@
+package kubecloudconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_contentHash(t *testing.T) {
+	a := &corev1.ConfigMap{Data: map[string]string{"cloud.conf": "a", "ca-bundle.pem": "b"}}
+	b := &corev1.ConfigMap{Data: map[string]string{"ca-bundle.pem": "b", "cloud.conf": "a"}}
+	assert.Equal(t, contentHash(a), contentHash(b))
+
+	// the same content in data and binary data is different content
+	c := &corev1.ConfigMap{Data: map[string]string{"cloud.conf": "a"}, BinaryData: map[string][]byte{"ca-bundle.pem": []byte("b")}}
+	assert.NotEqual(t, contentHash(a), contentHash(c))
+
+	setContentHash(a)
+	assert.Equal(t, contentHash(b), a.Annotations[ContentHashAnnotation])
+	assert.Equal(t, contentHash(b), contentHash(a), "the annotation is not part of the content")
+}
@@ -2,13 +2,15 @@ package kubecloudconfig
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
-	"github.com/openshift/api/features"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
 	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/cluster-config-operator/pkg/util"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -17,59 +19,86 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 const (
-	targetConfigName = "kube-cloud-config"
+	// TargetConfigName is the name of the kube-cloud-config ConfigMap in openshift-config-managed.
+	TargetConfigName = "kube-cloud-config"
 	targetConfigKey  = "cloud.conf"
 )
 
-// cloudConfigTransformer function transforms the input config map using the input infrastructure.congfig.openshift.io object.
-// only the data and binaryData field of the output ConfigMap will be respected by consumer of the transformer.
-type cloudConfigTransformer func(input *corev1.ConfigMap, key string, obj *configv1.Infrastructure) (output *corev1.ConfigMap, err error)
-
 // KubeCloudConfigController is responsible for managing the kube-cloud-config used by various Kubernetes components
 // as source for configuration on clouds/platform.
 // The controller uses the ConfigMap `openshift-config/<infrastructure.spec.cloudConfig.name>` and other platform specific
 // user specifications from `infrastructure.spec.platformSpec` to stitch together a new ConfigMap for kube cloud config.
 // The stitched ConfigMap is stored at `openshift-config-managed/kube-cloud-confg`.
+// The source and the target can be Secrets instead, see operatorclient.KubeCloudConfigOverrides.
 type KubeCloudConfigController struct {
+	operatorClient  operatorv1helpers.OperatorClient
 	infraClient     configv1client.InfrastructureInterface
 	infraLister     configv1listers.InfrastructureLister
 	configMapClient corev1client.ConfigMapsGetter
+	secretClient    corev1client.SecretsGetter
 
-	featureGateAccessor featuregates.FeatureGateAccess
+	// the source is read from openshift-config, the target from openshift-config-managed.
+	// The Secret listers are nil when the Secrets are not watched.
+	sourceConfigMapLister corev1listers.ConfigMapNamespaceLister
+	targetConfigMapLister corev1listers.ConfigMapNamespaceLister
+	sourceSecretLister    corev1listers.SecretNamespaceLister
+	targetSecretLister    corev1listers.SecretNamespaceLister
 
-	// transformers stores per platform tranformer
-	cloudConfigTransformers map[configv1.PlatformType]cloudConfigTransformer
+	featureGateAccessor featuregates.FeatureGateAccess
+	clock               clock.PassiveClock
 }
 
-// NewController returns a KubeCloudConfigController
+// NewController returns a KubeCloudConfigController. The Secret informers are only given when SecretsRequired at
+// startup, they are nil otherwise; Secrets selected later are reported as Degraded until the operator is restarted.
 func NewController(operatorClient operatorv1helpers.OperatorClient,
 	infraClient configv1client.InfrastructuresGetter, infraLister configv1listers.InfrastructureLister, infraInformer cache.SharedIndexInformer,
 	configMapClient corev1client.ConfigMapsGetter,
-	openshiftConfigConfigMapInformer cache.SharedIndexInformer, openshiftConfigManagedConfigMapInformer cache.SharedIndexInformer,
+	openshiftConfigConfigMapInformer corev1informers.ConfigMapInformer, openshiftConfigManagedConfigMapInformer corev1informers.ConfigMapInformer,
+	secretClient corev1client.SecretsGetter,
+	openshiftConfigSecretInformer corev1informers.SecretInformer, openshiftConfigManagedSecretInformer corev1informers.SecretInformer,
 	featureGateAccess featuregates.FeatureGateAccess,
+	clock clock.PassiveClock,
 	recorder events.Recorder) factory.Controller {
 	c := &KubeCloudConfigController{
-		infraClient:             infraClient.Infrastructures(),
-		infraLister:             infraLister,
-		configMapClient:         configMapClient,
-		cloudConfigTransformers: cloudConfigTransformers(),
-		featureGateAccessor:     featureGateAccess,
+		operatorClient:      operatorClient,
+		infraClient:         infraClient.Infrastructures(),
+		infraLister:         infraLister,
+		configMapClient:     configMapClient,
+		secretClient:        secretClient,
+		featureGateAccessor: featureGateAccess,
+		clock:               clock,
+
+		sourceConfigMapLister: openshiftConfigConfigMapInformer.Lister().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace),
+		targetConfigMapLister: openshiftConfigManagedConfigMapInformer.Lister().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace),
+	}
+	sourceInformers := []factory.Informer{openshiftConfigConfigMapInformer.Informer()}
+	targetInformers := []factory.Informer{openshiftConfigManagedConfigMapInformer.Informer()}
+	if openshiftConfigSecretInformer != nil && openshiftConfigManagedSecretInformer != nil {
+		c.sourceSecretLister = openshiftConfigSecretInformer.Lister().Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace)
+		c.targetSecretLister = openshiftConfigManagedSecretInformer.Lister().Secrets(operatorclient.GlobalMachineSpecifiedConfigNamespace)
+		sourceInformers = append(sourceInformers, openshiftConfigSecretInformer.Informer())
+		targetInformers = append(targetInformers, openshiftConfigManagedSecretInformer.Informer())
 	}
 
 	return factory.New().
 		WithInformers(
 			operatorClient.Informer(),
 			infraInformer,
-			openshiftConfigConfigMapInformer,
-			openshiftConfigManagedConfigMapInformer,
 		).
-		WithSync(c.sync).
+		// only the source and the target trigger a sync, not every ConfigMap and Secret of their namespaces
+		WithFilteredEventsInformers(c.isSource, sourceInformers...).
+		WithFilteredEventsInformers(factory.NamesFilter(TargetConfigName), targetInformers...).
+		WithSync(controllergate.Guard("KubeCloudConfigController", operatorClient, controllergate.Managed(operatorClient, c.sync, c.remove))).
 		WithSyncDegradedOnError(operatorClient).
 		ResyncEvery(time.Minute).
 		ToController("KubeCloudConfigController", recorder)
@@ -86,79 +115,254 @@ func (c *KubeCloudConfigController) sync(ctx context.Context, syncCtx factory.Sy
 	}
 
 	currentInfra := obj.DeepCopy()
-	var platformName configv1.PlatformType
-	if pstatus := currentInfra.Status.PlatformStatus; pstatus != nil {
-		platformName = pstatus.Type
+	platformName := util.PlatformType(currentInfra)
+	gen := &generation{platform: platformName, transformer: TransformerFor(platformName)}
+
+	// Check if this controller should manage the kube-cloud-config for this platform
+	gen.managed = gen.transformer.Owns(c.featureGateAccessor)
+	var syncErr error
+	if gen.managed {
+		syncErr = c.generate(ctx, syncCtx, currentInfra, gen)
+	} else {
+		// Set log level to 4 instead of using an event recorder due to this logging / happening every minute.
+		klog.V(4).Infof("KubeCloudConfigController: Skipping kube-cloud-config management for platform %s", platformName)
 	}
-	if len(platformName) == 0 {
-		syncCtx.Recorder().Warningf("KubeCloudConfigController", "Falling back to deprecated status.platform because infrastructures.%s/cluster status.platformStatus.type is empty", configv1.GroupName)
-		platformName = currentInfra.Status.Platform
+
+	return utilerrors.NewAggregate([]error{syncErr, c.applyGeneratedCondition(ctx, gen, syncErr)})
+}
+
+// isSource returns true if obj is the source named by infrastructure.spec.cloudConfig.name. The name is read from the
+// lister on every event, so that the filter follows changes of the Infrastructure; those trigger a sync on their own.
+func (c *KubeCloudConfigController) isSource(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
 	}
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		return false
+	}
+	infra, err := c.infraLister.Get("cluster")
+	if err != nil {
+		// not found is reported by the sync of the Infrastructure
+		return false
+	}
+	return len(infra.Spec.CloudConfig.Name) > 0 && metaObj.GetName() == infra.Spec.CloudConfig.Name
+}
 
-	// Check if this controller should manage the kube-cloud-config for this platform
-	shouldManage, err := c.shouldManageCloudConfig(platformName)
+// generate writes the kube-cloud-config for currentInfra, recording the source and the target in gen.
+func (c *KubeCloudConfigController) generate(ctx context.Context, syncCtx factory.SyncContext, currentInfra *configv1.Infrastructure, gen *generation) error {
+	spec, _, _, err := c.operatorClient.GetOperatorState()
 	if err != nil {
 		return err
 	}
-	if !shouldManage {
-		// Set log level to 4 instead of using an event recorder due to this logging / happening every minute.
-		klog.V(4).Infof("KubeCloudConfigController: Skipping kube-cloud-config management for platform %s", platformName)
-		return nil
+	overrides, err := operatorclient.GetUnsupportedConfigOverrides(spec)
+	if err != nil {
+		return err
+	}
+	sourceKind, targetKind, err := objectKinds(overrides.KubeCloudConfig)
+	if err != nil {
+		return err
+	}
+	if SecretsRequired(overrides.KubeCloudConfig) && c.sourceSecretLister == nil {
+		return fmt.Errorf("%s/%s Secrets are selected but not watched, restart the operator to watch them",
+			operatorclient.GlobalUserSpecifiedConfigNamespace, operatorclient.GlobalMachineSpecifiedConfigNamespace)
 	}
 
 	sourceCloudConfigMap := currentInfra.Spec.CloudConfig.Name
 	sourceCloudConfigKey := currentInfra.Spec.CloudConfig.Key
 
 	source := &corev1.ConfigMap{}
+	var sourceMeta metav1.ObjectMeta
 	if len(sourceCloudConfigMap) > 0 {
-		obj, err := c.configMapClient.ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace).Get(ctx, sourceCloudConfigMap, metav1.GetOptions{})
-		if err != nil {
-			return err
+		switch sourceKind {
+		case secretKind:
+			obj, err := c.sourceSecretLister.Get(sourceCloudConfigMap)
+			if err != nil {
+				return err
+			}
+			source = configMapFromSecret(obj)
+			sourceMeta = obj.ObjectMeta
+			gen.source = &generatedObject{kind: secretKind, namespace: obj.Namespace, name: obj.Name, resourceVersion: obj.ResourceVersion}
+		default:
+			obj, err := c.sourceConfigMapLister.Get(sourceCloudConfigMap)
+			if err != nil {
+				return err
+			}
+			obj.DeepCopyInto(source)
+			sourceMeta, source.ObjectMeta = source.ObjectMeta, metav1.ObjectMeta{}
+			gen.source = &generatedObject{kind: configMapKind, namespace: obj.Namespace, name: obj.Name, resourceVersion: obj.ResourceVersion}
 		}
-		obj.DeepCopyInto(source)
-		source.ObjectMeta = metav1.ObjectMeta{}
-	}
-
-	cloudConfigTransformerFn, ok := c.cloudConfigTransformers[platformName]
-	if !ok {
-		cloudConfigTransformerFn = asIsTransformer
 	}
 
-	target, err := cloudConfigTransformerFn(source, sourceCloudConfigKey, currentInfra)
+	target, err := transform(gen.transformer, source, sourceCloudConfigKey, currentInfra)
 	if err != nil {
 		return err
 	}
 
-	targetCloudConfigMap := targetConfigName
 	if len(target.Data) == 0 && len(target.BinaryData) == 0 { // delete if exists
-		err := c.configMapClient.ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Delete(ctx, targetCloudConfigMap, metav1.DeleteOptions{})
-		if err != nil && !apierrors.IsNotFound(err) {
+		if err := c.deleteConfigMapTarget(ctx, syncCtx, "no longer required"); err != nil {
 			return err
 		}
-		if err == nil {
-			syncCtx.Recorder().Eventf("KubeCloudConfigController", "%s/%s ConfigMap was deleted as no longer required", operatorclient.GlobalMachineSpecifiedConfigNamespace, targetCloudConfigMap)
-		}
-	} else { // apply the target
-		target.Name = targetCloudConfigMap
-		target.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
-		_, updated, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), target)
+		return c.deleteSecretTarget(ctx, syncCtx, "no longer required")
+	}
+
+	// apply the target, then delete the target of the other kind, so that consumers read exactly one kube-cloud-config.
+	target.Name = TargetConfigName
+	target.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
+	propagateMetadata(sourceMeta, &target.ObjectMeta)
+	gen.setAnnotations(&target.ObjectMeta)
+	setContentHash(target)
+	target.Annotations[GeneratedAtAnnotation] = c.clock.Now().UTC().Format(time.RFC3339)
+	if targetKind == secretKind {
+		secret, err := c.applySecretTarget(ctx, syncCtx, secretFromConfigMap(target), equalConfigsFunc(gen.transformer))
 		if err != nil {
 			return err
 		}
-		if updated {
-			syncCtx.Recorder().Eventf("KubeCloudConfigController", "%s/%s ConfigMap was updated", operatorclient.GlobalMachineSpecifiedConfigNamespace, targetCloudConfigMap)
+		gen.target = generatedTarget(secretKind, secret.ObjectMeta)
+		return c.deleteConfigMapTarget(ctx, syncCtx, "replaced by a Secret")
+	}
+	configMap, err := c.applyConfigMapTarget(ctx, syncCtx, target, equalConfigsFunc(gen.transformer))
+	if err != nil {
+		return err
+	}
+	gen.target = generatedTarget(configMapKind, configMap.ObjectMeta)
+	return c.deleteSecretTarget(ctx, syncCtx, "replaced by a ConfigMap")
+}
+
+// applyConfigMapTarget writes target unless the existing kube-cloud-config is up to date, comparing the cloud.conf with
+// equalConfigs, and returns the one in place.
+func (c *KubeCloudConfigController) applyConfigMapTarget(ctx context.Context, syncCtx factory.SyncContext, target *corev1.ConfigMap, equalConfigs func(a, b []byte) bool) (*corev1.ConfigMap, error) {
+	existing, err := c.targetConfigMapLister.Get(target.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if upToDate(existing, target, equalConfigs) {
+			klog.V(4).Infof("KubeCloudConfigController: %s/%s ConfigMap is up to date", target.Namespace, target.Name)
+			return existing, nil
 		}
+		target = target.DeepCopy()
+		removeStaleMetadata(existing.ObjectMeta, &target.ObjectMeta)
+		removeStaleGenerationAnnotations(existing.ObjectMeta, &target.ObjectMeta)
+	}
+
+	applied, updated, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, syncCtx.Recorder(), target)
+	if err != nil {
+		return nil, err
+	}
+	if updated {
+		syncCtx.Recorder().Eventf("KubeCloudConfigController", "%s/%s ConfigMap was updated", target.Namespace, target.Name)
+	}
+	return applied, nil
+}
+
+// applySecretTarget writes target unless the existing kube-cloud-config is up to date, comparing the cloud.conf with
+// equalConfigs, and returns the one in place.
+func (c *KubeCloudConfigController) applySecretTarget(ctx context.Context, syncCtx factory.SyncContext, target *corev1.Secret, equalConfigs func(a, b []byte) bool) (*corev1.Secret, error) {
+	existing, err := c.targetSecretLister.Get(target.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if secretUpToDate(existing, target, equalConfigs) {
+			klog.V(4).Infof("KubeCloudConfigController: %s/%s Secret is up to date", target.Namespace, target.Name)
+			return existing, nil
+		}
+		target = target.DeepCopy()
+		removeStaleMetadata(existing.ObjectMeta, &target.ObjectMeta)
+		removeStaleGenerationAnnotations(existing.ObjectMeta, &target.ObjectMeta)
+	}
+
+	applied, updated, err := resourceapply.ApplySecret(ctx, c.secretClient, syncCtx.Recorder(), target)
+	if err != nil {
+		return nil, err
 	}
+	if updated {
+		syncCtx.Recorder().Eventf("KubeCloudConfigController", "%s/%s Secret was updated", target.Namespace, target.Name)
+	}
+	return applied, nil
+}
 
+// deleteConfigMapTarget deletes the kube-cloud-config ConfigMap if it exists, reporting why.
+// The existence is checked in the lister first, so that a sync does not write when there is nothing to delete.
+func (c *KubeCloudConfigController) deleteConfigMapTarget(ctx context.Context, syncCtx factory.SyncContext, reason string) error {
+	_, err := c.targetConfigMapLister.Get(TargetConfigName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = c.configMapClient.ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Delete(ctx, TargetConfigName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	syncCtx.Recorder().Eventf("KubeCloudConfigController", "%s/%s ConfigMap was deleted as %s", operatorclient.GlobalMachineSpecifiedConfigNamespace, TargetConfigName, reason)
+	return nil
+}
+
+// deleteSecretTarget deletes the kube-cloud-config Secret if it exists, reporting why.
+// The existence is checked in the lister first, so that a sync does not write when there is nothing to delete. When
+// the Secrets are not watched, no Secret target has been written since the operator started and nothing is deleted.
+func (c *KubeCloudConfigController) deleteSecretTarget(ctx context.Context, syncCtx factory.SyncContext, reason string) error {
+	if c.targetSecretLister == nil {
+		return nil
+	}
+	_, err := c.targetSecretLister.Get(TargetConfigName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = c.secretClient.Secrets(operatorclient.GlobalMachineSpecifiedConfigNamespace).Delete(ctx, TargetConfigName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	syncCtx.Recorder().Eventf("KubeCloudConfigController", "%s/%s Secret was deleted as %s", operatorclient.GlobalMachineSpecifiedConfigNamespace, TargetConfigName, reason)
 	return nil
 }
 
-// asIsTransformer implements cloudConfigTransformer and copies the input ConfigMap as-is to the output ConfigMap.
+// upToDate returns true if existing has the content, the propagated metadata and the generation annotations of target,
+// and was not modified since it was written. The cloud.conf is compared with equalConfigs, so that a semantically
+// equal one is kept along with its content hash.
+func upToDate(existing, target *corev1.ConfigMap, equalConfigs func(a, b []byte) bool) bool {
+	return existing.Annotations[ContentHashAnnotation] == contentHash(existing) && equalContent(existing, target, equalConfigs) &&
+		equalPropagatedMetadata(existing.ObjectMeta, target.ObjectMeta) && equalGenerationAnnotations(existing.ObjectMeta, target.ObjectMeta)
+}
+
+// remove deletes the kube-cloud-config while the operator is Removed.
+// Platforms for which the kube-cloud-config is managed by another operator are left alone.
+func (c *KubeCloudConfigController) remove(ctx context.Context, syncCtx factory.SyncContext) error {
+	infra, err := c.infraLister.Get("cluster")
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !TransformerFor(util.PlatformType(infra)).Owns(c.featureGateAccessor) {
+		return nil
+	}
+
+	if err := c.deleteConfigMapTarget(ctx, syncCtx, "the operator is Removed"); err != nil {
+		return err
+	}
+	return c.deleteSecretTarget(ctx, syncCtx, "the operator is Removed")
+}
+
+// asIsTransformer copies the input ConfigMap as-is to the output ConfigMap.
 // this ensure that the input cloud conf is stored at `targetConfigKey` for the output.
 func asIsTransformer(input *corev1.ConfigMap, sourceKey string, _ *configv1.Infrastructure) (*corev1.ConfigMap, error) {
 	output := input.DeepCopy()
 	output.Namespace = operatorclient.GlobalMachineSpecifiedConfigNamespace
-	output.Name = targetConfigName
+	output.Name = TargetConfigName
 	delete(output.Data, sourceKey)
 	delete(output.BinaryData, sourceKey)
 
@@ -171,62 +375,18 @@ func asIsTransformer(input *corev1.ConfigMap, sourceKey string, _ *configv1.Infr
 	return output, nil
 }
 
-// cloudConfigTransformers returns all configured cloud transformers
-func cloudConfigTransformers() map[configv1.PlatformType]cloudConfigTransformer {
-	cloudConfigTransformers := map[configv1.PlatformType]cloudConfigTransformer{
-		configv1.AWSPlatformType:   awsTransformer,
-		configv1.AzurePlatformType: azureTransformer,
-	}
-	return cloudConfigTransformers
-}
-
-// shouldManageCloudConfig determines whether this controller should manage the kube-cloud-config
-// ConfigMap for the given platform type. This allows for platform-specific logic to determine
-// when ownership should transfer to another operator.
-func (c *KubeCloudConfigController) shouldManageCloudConfig(platformType configv1.PlatformType) (bool, error) {
-	switch platformType {
-	case configv1.VSpherePlatformType:
-		// For vSphere, check if VSphereMultiVCenterDay2 feature gate is enabled
-		// When enabled, ownership transfers to cluster-cloud-controller-manager-operator
-		enabled := c.isFeatureGateEnabled(features.FeatureGateVSphereMultiVCenterDay2)
-		// Return false (do not manage) if enabled, true (manage) if not enabled
-		return !enabled, nil
-
-	case configv1.AWSPlatformType,
-		configv1.AzurePlatformType,
-		configv1.GCPPlatformType,
-		configv1.OpenStackPlatformType,
-		configv1.OvirtPlatformType,
-		configv1.KubevirtPlatformType,
-		configv1.IBMCloudPlatformType,
-		configv1.PowerVSPlatformType,
-		configv1.AlibabaCloudPlatformType,
-		configv1.NutanixPlatformType,
-		configv1.ExternalPlatformType,
-		configv1.NonePlatformType,
-		configv1.EquinixMetalPlatformType,
-		configv1.BareMetalPlatformType:
-		// For all other platforms, this controller manages the cloud config
-		return true, nil
-
-	default:
-		// Unknown platform type, default to managing
-		return true, nil
-	}
-}
-
 // isFeatureGateEnabled checks if the specified feature gate is enabled in the cluster.
 // It uses the feature gates that were retrieved during controller initialization.
 // If feature gates weren't available at initialization, it returns false as a safe fallback.
-func (c *KubeCloudConfigController) isFeatureGateEnabled(gateName configv1.FeatureGateName) bool {
-	if !c.featureGateAccessor.AreInitialFeatureGatesObserved() {
+func isFeatureGateEnabled(featureGateAccessor featuregates.FeatureGateAccess, gateName configv1.FeatureGateName) bool {
+	if featureGateAccessor == nil || !featureGateAccessor.AreInitialFeatureGatesObserved() {
 		// Feature gates weren't initialized, return safe fallback
 		klog.Warningf("unable to check featuregate %v due to currentFeatureGates == nil", gateName)
 		return false
 	}
 
 	// We can ignore error since only time error happens if initial feature gates were not observed and we checked above
-	currentFeatureGates, _ := c.featureGateAccessor.CurrentFeatureGates()
+	currentFeatureGates, _ := featureGateAccessor.CurrentFeatureGates()
 	klog.V(4).Infof("is featuregate %v enabled?  %v", gateName, currentFeatureGates.Enabled(gateName))
 	return currentFeatureGates.Enabled(gateName)
 }
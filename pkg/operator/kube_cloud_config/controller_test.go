@@ -7,15 +7,19 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/api/features"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	configfakeclient "github.com/openshift/client-go/config/clientset/versioned/fake"
 	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
 	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/fake"
 	ktesting "k8s.io/client-go/testing"
@@ -28,16 +32,16 @@ func Test_asIsTransformer(t *testing.T) {
 		input, output *corev1.ConfigMap
 	}{{
 		input:  &corev1.ConfigMap{},
-		output: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace}},
+		output: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace}},
 	}, {
 		input:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "something", Namespace: "something-else"}},
-		output: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace}},
+		output: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace}},
 	}, {
 		input: &corev1.ConfigMap{
 			Data: map[string]string{"config": "someval"},
 		},
 		output: &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
+			ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
 			Data:       map[string]string{"cloud.conf": "someval"},
 		},
 	}, {
@@ -45,7 +49,7 @@ func Test_asIsTransformer(t *testing.T) {
 			BinaryData: map[string][]byte{"config": []byte("someval")},
 		},
 		output: &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
+			ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
 			BinaryData: map[string][]byte{"cloud.conf": []byte("someval")},
 		},
 	}, {
@@ -53,7 +57,7 @@ func Test_asIsTransformer(t *testing.T) {
 			Data: map[string]string{"config": "someval", "ca-bundle": "bundle"},
 		},
 		output: &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
+			ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
 			Data:       map[string]string{"cloud.conf": "someval", "ca-bundle": "bundle"},
 		},
 	}, {
@@ -62,7 +66,7 @@ func Test_asIsTransformer(t *testing.T) {
 			BinaryData: map[string][]byte{"ca-bundle": []byte("bundle")},
 		},
 		output: &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
+			ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
 			Data:       map[string]string{"cloud.conf": "someval"},
 			BinaryData: map[string][]byte{"ca-bundle": []byte("bundle")},
 		},
@@ -71,7 +75,7 @@ func Test_asIsTransformer(t *testing.T) {
 			Data: map[string]string{"ca-bundle": "bundle"},
 		},
 		output: &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
+			ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
 			Data:       map[string]string{"ca-bundle": "bundle"},
 		},
 	}, {
@@ -79,7 +83,7 @@ func Test_asIsTransformer(t *testing.T) {
 			BinaryData: map[string][]byte{"ca-bundle": []byte("bundle")},
 		},
 		output: &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{Name: targetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
+			ObjectMeta: metav1.ObjectMeta{Name: TargetConfigName, Namespace: operatorclient.GlobalMachineSpecifiedConfigNamespace},
 			BinaryData: map[string][]byte{"ca-bundle": []byte("bundle")},
 		},
 	}}
@@ -108,7 +112,6 @@ somekey = somevalue`,
 		outputdata: map[string]string{"cloud.conf": `[global]
 somekey = somevalue`},
 		actions: []ktesting.Action{
-			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config", "cluster-config-v1"),
 			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", "kube-cloud-config"),
 			ktesting.NewUpdateAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", nil),
 		},
@@ -148,7 +151,6 @@ SubnetID = subnet-test`,
 VPC = vpc-test
 SubnetID = subnet-test`},
 		actions: []ktesting.Action{
-			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config", "cluster-config-v1"),
 			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", "kube-cloud-config"),
 			ktesting.NewUpdateAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", nil),
 		},
@@ -168,7 +170,6 @@ SubnetID = subnet-test
 	SigningRegion = test-region
 `},
 		actions: []ktesting.Action{
-			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config", "cluster-config-v1"),
 			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", "kube-cloud-config"),
 			ktesting.NewUpdateAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", nil),
 		},
@@ -182,7 +183,6 @@ SubnetID = subnet-test
 }
 `},
 		actions: []ktesting.Action{
-			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config", "cluster-config-v1"),
 			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", "kube-cloud-config"),
 			ktesting.NewUpdateAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", nil),
 		},
@@ -196,13 +196,13 @@ SubnetID = subnet-test
 }
 `},
 		actions: []ktesting.Action{
-			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config", "cluster-config-v1"),
 			ktesting.NewGetAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", "kube-cloud-config"),
 			ktesting.NewUpdateAction(schema.GroupVersionResource{Resource: "configmaps"}, "openshift-config-managed", nil),
 		},
 	}}
 	for _, test := range cases {
 		t.Run("", func(t *testing.T) {
+			secrets := fake.NewSimpleClientset()
 			fake := fake.NewSimpleClientset()
 			if len(test.inputdata) > 0 {
 				fake.Tracker().Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "openshift-config"}, Data: map[string]string{"config": test.inputdata}})
@@ -221,11 +221,17 @@ SubnetID = subnet-test
 			featureGateAccessor := featuregates.NewHardcodedFeatureGateAccess(nil, nil)
 
 			ctrl := KubeCloudConfigController{
-				infraClient:             fakeConfig.ConfigV1().Infrastructures(),
-				infraLister:             configv1listers.NewInfrastructureLister(indexerInfra),
-				configMapClient:         fake.CoreV1(),
-				featureGateAccessor:     featureGateAccessor,
-				cloudConfigTransformers: cloudConfigTransformers(),
+				infraClient:           fakeConfig.ConfigV1().Infrastructures(),
+				infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+				operatorClient:        operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+				configMapClient:       fake.CoreV1(),
+				secretClient:          secrets.CoreV1(),
+				sourceConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config"},
+				targetConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config-managed"},
+				sourceSecretLister:    secretTrackerLister{secrets.Tracker(), "openshift-config"},
+				targetSecretLister:    secretTrackerLister{secrets.Tracker(), "openshift-config-managed"},
+				featureGateAccessor:   featureGateAccessor,
+				clock:                 clocktesting.NewFakePassiveClock(time.Now()),
 			}
 
 			err := ctrl.sync(context.TODO(),
@@ -259,6 +265,74 @@ SubnetID = subnet-test
 	}
 }
 
+func Test_sync_skipsEqualWrites(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cluster-config-v1", Key: "config"}},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "test-region"}}},
+	}
+	fake := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "openshift-config"},
+		Data:       map[string]string{"config": "[Global]\nVPC = vpc-test\nSubnetID = subnet-test\n"},
+	})
+	indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexerInfra.Add(infra); err != nil {
+		t.Fatal(err.Error())
+	}
+	ctrl := KubeCloudConfigController{
+		infraClient:           configfakeclient.NewClientset(infra).ConfigV1().Infrastructures(),
+		infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+		operatorClient:        operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+		configMapClient:       fake.CoreV1(),
+		secretClient:          fake.CoreV1(),
+		sourceConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config"},
+		targetConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config-managed"},
+		sourceSecretLister:    secretTrackerLister{fake.Tracker(), "openshift-config"},
+		targetSecretLister:    secretTrackerLister{fake.Tracker(), "openshift-config-managed"},
+		featureGateAccessor:   featuregates.NewHardcodedFeatureGateAccess(nil, nil),
+		clock:                 clocktesting.NewFakePassiveClock(time.Now()),
+	}
+	syncCtx := factory.NewSyncContext("KubeCloudConfigController", events.NewInMemoryRecorder("KubeCloudConfigController", clocktesting.NewFakePassiveClock(time.Now())))
+
+	// the first sync creates the target with its content hash
+	assert.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	target, err := fake.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, contentHash(target), target.Annotations[ContentHashAnnotation])
+
+	// the unchanged source is not written again
+	fake.ClearActions()
+
+	assert.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	for _, a := range fake.Actions() {
+		assert.Equalf(t, "get", a.GetVerb(), "unexpected %s of %s", a.GetVerb(), a.GetResource().Resource)
+	}
+
+	// a source only reordered and reformatted is not written either, the target keeps its content hash
+	source, err := fake.CoreV1().ConfigMaps("openshift-config").Get(context.TODO(), "cluster-config-v1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	source.Data["config"] = "[global]\n\tSubnetID = subnet-test\n\tVPC = vpc-test\n"
+	_, err = fake.CoreV1().ConfigMaps("openshift-config").Update(context.TODO(), source, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	fake.ClearActions()
+
+	assert.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	for _, a := range fake.Actions() {
+		assert.Equalf(t, "get", a.GetVerb(), "unexpected %s of %s", a.GetVerb(), a.GetResource().Resource)
+	}
+
+	// a target modified by someone else is written again
+	target.Data["cloud.conf"] = "[Global]\n\tVPC = vpc-other\n"
+	_, err = fake.CoreV1().ConfigMaps("openshift-config-managed").Update(context.TODO(), target, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	fake.ClearActions()
+
+	assert.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	got, err := fake.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[global]\n\tSubnetID = subnet-test\n\tVPC = vpc-test\n", got.Data["cloud.conf"])
+}
+
 func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -281,15 +355,15 @@ func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 			platformType:       configv1.VSpherePlatformType,
 			inputData:          `[Global]\ntest = value`,
 			featureGateEnabled: false,
-			expectedActions:    3, // Get source config, Get target config, Update target config
+			expectedActions:    2, // Get target config, Update target config
 			description:        "Should update ConfigMap when VSphereMultiVCenterDay2 is disabled on vSphere",
 		},
 		{
 			name:               "AWS platform with feature gate enabled",
 			platformType:       configv1.AWSPlatformType,
-			inputData:          `[Global]\nVPC = vpc-test`,
+			inputData:          "[Global]\nVPC = vpc-test",
 			featureGateEnabled: true,
-			expectedActions:    3, // Get source config, Get target config, Update target config
+			expectedActions:    2, // Get target config, Update target config
 			description:        "Should update ConfigMap on AWS even if VSphereMultiVCenterDay2 is enabled",
 		},
 		{
@@ -297,7 +371,7 @@ func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 			platformType:       configv1.AzurePlatformType,
 			inputData:          `{"resourceGroup":"test-rg"}`,
 			featureGateEnabled: true,
-			expectedActions:    3, // Get source config, Get target config, Update target config
+			expectedActions:    2, // Get target config, Update target config
 			description:        "Should update ConfigMap on Azure even if VSphereMultiVCenterDay2 is enabled",
 		},
 		{
@@ -305,7 +379,7 @@ func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 			platformType:       configv1.GCPPlatformType,
 			inputData:          `[global]\nsomekey = somevalue`,
 			featureGateEnabled: true,
-			expectedActions:    3, // Get source config, Get target config, Update target config
+			expectedActions:    2, // Get target config, Update target config
 			description:        "Should update ConfigMap on GCP even if VSphereMultiVCenterDay2 is enabled",
 		},
 	}
@@ -348,6 +422,7 @@ func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 				)
 			}
 
+			secrets := fake.NewClientset()
 			fake := fake.NewClientset()
 			if err := fake.Tracker().Add(&corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "openshift-config"},
@@ -369,11 +444,17 @@ func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 			fakeConfig := configfakeclient.NewClientset(inputInfra)
 
 			ctrl := KubeCloudConfigController{
-				infraClient:             fakeConfig.ConfigV1().Infrastructures(),
-				infraLister:             configv1listers.NewInfrastructureLister(indexerInfra),
-				configMapClient:         fake.CoreV1(),
-				featureGateAccessor:     featureGateAccessor,
-				cloudConfigTransformers: cloudConfigTransformers(),
+				infraClient:           fakeConfig.ConfigV1().Infrastructures(),
+				infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+				operatorClient:        operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+				configMapClient:       fake.CoreV1(),
+				secretClient:          secrets.CoreV1(),
+				sourceConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config"},
+				targetConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config-managed"},
+				sourceSecretLister:    secretTrackerLister{secrets.Tracker(), "openshift-config"},
+				targetSecretLister:    secretTrackerLister{secrets.Tracker(), "openshift-config-managed"},
+				featureGateAccessor:   featureGateAccessor,
+				clock:                 clocktesting.NewFakePassiveClock(time.Now()),
 			}
 
 			err := ctrl.sync(context.TODO(),
@@ -384,3 +465,141 @@ func Test_sync_withVSphereMultiVCenterDay2FeatureGate(t *testing.T) {
 		})
 	}
 }
+
+func Test_isSource(t *testing.T) {
+	indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	ctrl := KubeCloudConfigController{infraLister: configv1listers.NewInfrastructureLister(indexerInfra)}
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openshift-config"}}
+	}
+
+	assert.False(t, ctrl.isSource(configMap("cloud-provider-config")), "no Infrastructure")
+
+	infra := &configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	assert.NoError(t, indexerInfra.Add(infra))
+	assert.False(t, ctrl.isSource(configMap("cloud-provider-config")), "no cloud config")
+	assert.False(t, ctrl.isSource(configMap("")), "no cloud config")
+
+	infra = infra.DeepCopy()
+	infra.Spec.CloudConfig = configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}
+	assert.NoError(t, indexerInfra.Update(infra))
+	assert.True(t, ctrl.isSource(configMap("cloud-provider-config")))
+	assert.True(t, ctrl.isSource(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}}))
+	assert.True(t, ctrl.isSource(cache.DeletedFinalStateUnknown{Key: "openshift-config/cloud-provider-config", Obj: configMap("cloud-provider-config")}))
+	assert.False(t, ctrl.isSource(configMap("unrelated")))
+
+	// the filter follows the Infrastructure
+	infra = infra.DeepCopy()
+	infra.Spec.CloudConfig.Name = "renamed-config"
+	assert.NoError(t, indexerInfra.Update(infra))
+	assert.True(t, ctrl.isSource(configMap("renamed-config")))
+	assert.False(t, ctrl.isSource(configMap("cloud-provider-config")))
+}
+
+func Test_remove(t *testing.T) {
+	cases := []struct {
+		name         string
+		platform     configv1.PlatformType
+		enabledGates []configv1.FeatureGateName
+		deleted      bool
+	}{{
+		name:     "managed platform",
+		platform: configv1.AWSPlatformType,
+		deleted:  true,
+	}, {
+		name:         "platform managed by another operator",
+		platform:     configv1.VSpherePlatformType,
+		enabledGates: []configv1.FeatureGateName{features.FeatureGateVSphereMultiVCenterDay2},
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: test.platform}},
+			}
+			indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			assert.NoError(t, indexerInfra.Add(infra))
+			fake := fake.NewSimpleClientset(
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed"}},
+			)
+
+			ctrl := KubeCloudConfigController{
+				infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+				configMapClient:       fake.CoreV1(),
+				secretClient:          fake.CoreV1(),
+				sourceConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config"},
+				targetConfigMapLister: configMapTrackerLister{fake.Tracker(), "openshift-config-managed"},
+				sourceSecretLister:    secretTrackerLister{fake.Tracker(), "openshift-config"},
+				targetSecretLister:    secretTrackerLister{fake.Tracker(), "openshift-config-managed"},
+				featureGateAccessor:   featuregates.NewHardcodedFeatureGateAccess(test.enabledGates, nil),
+				clock:                 clocktesting.NewFakePassiveClock(time.Now()),
+			}
+			err := ctrl.remove(context.TODO(),
+				factory.NewSyncContext("KubeCloudConfigController", events.NewInMemoryRecorder("KubeCloudConfigController", clocktesting.NewFakePassiveClock(time.Now()))))
+			assert.NoError(t, err)
+
+			_, err = fake.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+			assert.Equal(t, test.deleted, apierrors.IsNotFound(err))
+			_, err = fake.CoreV1().Secrets("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+			assert.Equal(t, test.deleted, apierrors.IsNotFound(err))
+		})
+	}
+}
+
+// configMapTrackerLister reads the ConfigMaps of a namespace from the object tracker of a fake clientset. Unlike a
+// lister fed by an informer, it is never stale and does not record actions.
+type configMapTrackerLister struct {
+	tracker   ktesting.ObjectTracker
+	namespace string
+}
+
+func (l configMapTrackerLister) List(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	obj, err := l.tracker.List(corev1.SchemeGroupVersion.WithResource("configmaps"), corev1.SchemeGroupVersion.WithKind("ConfigMap"), l.namespace)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*corev1.ConfigMap
+	for i := range obj.(*corev1.ConfigMapList).Items {
+		if cm := &obj.(*corev1.ConfigMapList).Items[i]; selector.Matches(labels.Set(cm.Labels)) {
+			ret = append(ret, cm)
+		}
+	}
+	return ret, nil
+}
+
+func (l configMapTrackerLister) Get(name string) (*corev1.ConfigMap, error) {
+	obj, err := l.tracker.Get(corev1.SchemeGroupVersion.WithResource("configmaps"), l.namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*corev1.ConfigMap), nil
+}
+
+// secretTrackerLister is the configMapTrackerLister of Secrets.
+type secretTrackerLister struct {
+	tracker   ktesting.ObjectTracker
+	namespace string
+}
+
+func (l secretTrackerLister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	obj, err := l.tracker.List(corev1.SchemeGroupVersion.WithResource("secrets"), corev1.SchemeGroupVersion.WithKind("Secret"), l.namespace)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*corev1.Secret
+	for i := range obj.(*corev1.SecretList).Items {
+		if secret := &obj.(*corev1.SecretList).Items[i]; selector.Matches(labels.Set(secret.Labels)) {
+			ret = append(ret, secret)
+		}
+	}
+	return ret, nil
+}
+
+func (l secretTrackerLister) Get(name string) (*corev1.Secret, error) {
+	obj, err := l.tracker.Get(corev1.SchemeGroupVersion.WithResource("secrets"), l.namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*corev1.Secret), nil
+}
@@ -1,3 +1,28 @@
 // Package kubecloudconfig controller is responsible for stitching the user-provided Kubernetes cloud configuration file and
 // the various platform specific settings provided in the infrastructures.config.openshift.io
+//
+// The platform specific behavior, including rendering at bootstrap, is implemented by a Transformer per platform,
+// registered with RegisterTransformer. Platforms without one get the user-provided cloud config as-is. The AWS and Azure
+// cloud configs are copied byte-for-byte unless a value has to be added to them. On Azure Stack Hub the Azure
+// environment file is generated at the endpoints key from status.platformStatus.azure.armEndpoint, other fields like the
+// token audience are taken from the endpoints key of the user-provided cloud config.
+//
+// Keys of the user-provided cloud config other than the cloud.conf key, like CA bundles, are copied unchanged. Its
+// labels and annotations are only propagated when their key prefix is cloud-config.openshift.io or one of its
+// subdomains, and propagated keys removed from the source are removed from the kube-cloud-config.
+//
+// Each write records a hash of the data in ContentHashAnnotation, the transformer and the user-provided cloud config in
+// the generationAnnotations, and the time in GeneratedAtAnnotation. The kube-cloud-config is not written when its
+// content already matches, or only differs in the order of keys and whitespace of a cloud.conf compared by a
+// ConfigComparer. The GeneratedConditionType condition reports the last generation along with the resourceVersion of
+// the user-provided cloud config, or the error and the last generation when it fails.
+//
+// The user-provided cloud config and the kube-cloud-config can be Secrets instead of ConfigMaps, selected with
+// spec.unsupportedConfigOverrides.kubeCloudConfig.sourceKind and targetKind of the operator Config. A Secret source
+// requires a Secret target. Consumers read the kube-cloud-config of the target kind, the one of the other kind is
+// deleted. The Secrets are only watched when a Secret kind is selected at startup, one selected later is reported as
+// Degraded until the operator is restarted. The bootstrap rendering always produces the ConfigMap.
+//
+// The controller only syncs on changes of the source named by spec.cloudConfig.name of the Infrastructure and of the
+// kube-cloud-config, both read from informer caches.
 package kubecloudconfig
@@ -0,0 +1,203 @@
+package kubecloudconfig
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GeneratedConditionType is the operator condition reporting the last generation of the kube-cloud-config.
+const GeneratedConditionType = "KubeCloudConfigControllerGenerated"
+
+const (
+	// TransformerAnnotation records the platform of the Transformer that generated the kube-cloud-config.
+	TransformerAnnotation = "kube-cloud-config.config.openshift.io/transformer"
+	// SourceAnnotation records the <namespace>/<name> of the user-provided cloud config the kube-cloud-config was
+	// generated from. It is not set when there is none.
+	SourceAnnotation = "kube-cloud-config.config.openshift.io/source"
+	// SourceKindAnnotation records the kind of the user-provided cloud config, ConfigMap or Secret.
+	SourceKindAnnotation = "kube-cloud-config.config.openshift.io/source-kind"
+	// GeneratedAtAnnotation records when the kube-cloud-config was last written, in RFC 3339 format.
+	GeneratedAtAnnotation = "kube-cloud-config.config.openshift.io/generated-at"
+)
+
+// generationAnnotations are the annotations recording the generation of the kube-cloud-config. A change of their
+// values rewrites the kube-cloud-config, unlike the resourceVersion of the source or GeneratedAtAnnotation, so that
+// metadata-only edits of the source do not roll out to the consumers.
+var generationAnnotations = []string{TransformerAnnotation, SourceAnnotation, SourceKindAnnotation}
+
+// generation describes a sync of the kube-cloud-config. It is recorded in the generationAnnotations of the
+// kube-cloud-config, and reported in the GeneratedConditionType condition along with the resourceVersion of the source.
+type generation struct {
+	platform    configv1.PlatformType
+	transformer Transformer
+	// managed is false when the kube-cloud-config of the platform is managed by another operator.
+	managed bool
+	// source is the user-provided cloud config, nil when there is none.
+	source *generatedObject
+	// target is the kube-cloud-config, nil when it is not required.
+	target *generatedObject
+}
+
+// generatedObject identifies an object of a generation.
+type generatedObject struct {
+	kind            string
+	namespace       string
+	name            string
+	resourceVersion string
+	contentHash     string
+	generatedAt     string
+}
+
+// generatedTarget returns the kube-cloud-config of the given kind with the given metadata as a generatedObject.
+func generatedTarget(kind string, meta metav1.ObjectMeta) *generatedObject {
+	return &generatedObject{
+		kind:        kind,
+		namespace:   meta.Namespace,
+		name:        meta.Name,
+		contentHash: meta.Annotations[ContentHashAnnotation],
+		generatedAt: meta.Annotations[GeneratedAtAnnotation],
+	}
+}
+
+func (g *generation) String() string {
+	s := fmt.Sprintf("with the %s transformer for platform %s", g.transformer.Platform(), g.platform)
+	if g.source != nil {
+		s += fmt.Sprintf(" from %s/%s %s resourceVersion %s", g.source.namespace, g.source.name, g.source.kind, g.source.resourceVersion)
+	}
+	return s
+}
+
+// setAnnotations records the transformer and the source of g in the generationAnnotations of target.
+func (g *generation) setAnnotations(target *metav1.ObjectMeta) {
+	if target.Annotations == nil {
+		target.Annotations = map[string]string{}
+	}
+	target.Annotations[TransformerAnnotation] = string(g.transformer.Platform())
+	if g.source != nil {
+		target.Annotations[SourceAnnotation] = g.source.namespace + "/" + g.source.name
+		target.Annotations[SourceKindAnnotation] = g.source.kind
+	}
+}
+
+// equalGenerationAnnotations returns true if existing and target have the same generationAnnotations.
+func equalGenerationAnnotations(existing, target metav1.ObjectMeta) bool {
+	for _, k := range generationAnnotations {
+		if existing.Annotations[k] != target.Annotations[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// removeStaleGenerationAnnotations marks the generationAnnotations of existing that are not in target for removal,
+// see removeStaleMetadata.
+func removeStaleGenerationAnnotations(existing metav1.ObjectMeta, target *metav1.ObjectMeta) {
+	for _, k := range generationAnnotations {
+		if _, ok := existing.Annotations[k]; !ok {
+			continue
+		}
+		if _, ok := target.Annotations[k]; !ok {
+			if target.Annotations == nil {
+				target.Annotations = map[string]string{}
+			}
+			target.Annotations[k+"-"] = ""
+		}
+	}
+}
+
+// lastGenerated returns a description of the generation recorded in the annotations of the kube-cloud-config of
+// the given kind, or an empty string if it has none.
+func lastGenerated(kind string, meta metav1.ObjectMeta) string {
+	hash, transformer := meta.Annotations[ContentHashAnnotation], meta.Annotations[TransformerAnnotation]
+	if hash == "" || transformer == "" {
+		return ""
+	}
+	s := fmt.Sprintf("%s/%s %s with content hash %s was generated%s with the %s transformer", meta.Namespace, meta.Name, kind, hash,
+		generatedAt(meta.Annotations[GeneratedAtAnnotation]), transformer)
+	if source := meta.Annotations[SourceAnnotation]; source != "" {
+		s += fmt.Sprintf(" from %s %s", source, meta.Annotations[SourceKindAnnotation])
+	}
+	return s
+}
+
+// generatedAt returns " at <t>", or an empty string if t is empty.
+func generatedAt(t string) string {
+	if t == "" {
+		return ""
+	}
+	return " at " + t
+}
+
+// generatedCondition returns the GeneratedConditionType condition for the sync described by gen that failed with
+// syncErr, if any. Failed syncs report the last generation, see lastGenerated. The message only changes with the
+// generation, so that the operator status is not written on every sync.
+func generatedCondition(gen *generation, syncErr error, last string) *applyoperatorv1.OperatorConditionApplyConfiguration {
+	condition := applyoperatorv1.OperatorCondition().WithType(GeneratedConditionType)
+
+	var status operatorv1.ConditionStatus
+	var reason, message string
+	switch {
+	case !gen.managed:
+		status, reason = operatorv1.ConditionFalse, "NotManaged"
+		message = fmt.Sprintf("the kube-cloud-config of platform %s is managed by another operator", gen.platform)
+	case syncErr != nil:
+		status, reason = operatorv1.ConditionFalse, "GenerationFailed"
+		message = fmt.Sprintf("generation %s failed: %v", gen, syncErr)
+		if last != "" {
+			message += "; last generated: " + last
+		}
+	case gen.target == nil:
+		status, reason = operatorv1.ConditionTrue, "NotRequired"
+		message = fmt.Sprintf("no kube-cloud-config is required %s", gen)
+	default:
+		status, reason = operatorv1.ConditionTrue, "Generated"
+		message = fmt.Sprintf("%s/%s %s with content hash %s was generated%s %s", gen.target.namespace, gen.target.name, gen.target.kind,
+			gen.target.contentHash, generatedAt(gen.target.generatedAt), gen)
+	}
+	return condition.WithStatus(status).WithReason(reason).WithMessage(message)
+}
+
+// lastGeneratedTarget returns the description of the generation recorded in the existing kube-cloud-config, preferring
+// the Secret when the Secrets are watched.
+func (c *KubeCloudConfigController) lastGeneratedTarget() (string, error) {
+	if c.targetSecretLister != nil {
+		secret, err := c.targetSecretLister.Get(TargetConfigName)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		if err == nil {
+			if last := lastGenerated(secretKind, secret.ObjectMeta); last != "" {
+				return last, nil
+			}
+		}
+	}
+	configMap, err := c.targetConfigMapLister.Get(TargetConfigName)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return lastGenerated(configMapKind, configMap.ObjectMeta), nil
+}
+
+// applyGeneratedCondition records the sync described by gen in the GeneratedConditionType condition.
+func (c *KubeCloudConfigController) applyGeneratedCondition(ctx context.Context, gen *generation, syncErr error) error {
+	var last string
+	if syncErr != nil && gen.managed {
+		var err error
+		if last, err = c.lastGeneratedTarget(); err != nil {
+			return err
+		}
+	}
+	return c.operatorClient.ApplyOperatorStatus(ctx,
+		factory.ControllerFieldManager("KubeCloudConfigController", "generation"),
+		applyoperatorv1.OperatorStatus().WithConditions(generatedCondition(gen, syncErr, last)))
+}
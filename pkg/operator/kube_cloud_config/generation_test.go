@@ -0,0 +1,178 @@
+package kubecloudconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+)
+
+func Test_generatedCondition(t *testing.T) {
+	source := &generatedObject{kind: configMapKind, namespace: "openshift-config", name: "cloud-provider-config", resourceVersion: "42"}
+	generated := &generation{
+		platform:    configv1.AWSPlatformType,
+		transformer: awsTransformer{},
+		managed:     true,
+		source:      source,
+		target:      &generatedObject{kind: configMapKind, namespace: "openshift-config-managed", name: "kube-cloud-config", contentHash: "abc", generatedAt: "2024-01-02T03:04:05Z"},
+	}
+	const last = "openshift-config-managed/kube-cloud-config ConfigMap with content hash abc was generated at 2024-01-02T03:04:05Z with the AWS transformer from openshift-config/cloud-provider-config ConfigMap"
+
+	cases := []struct {
+		name string
+		gen  *generation
+		err  error
+		last string
+
+		status  operatorv1.ConditionStatus
+		reason  string
+		message string
+	}{{
+		name:    "generated",
+		gen:     generated,
+		status:  operatorv1.ConditionTrue,
+		reason:  "Generated",
+		message: "openshift-config-managed/kube-cloud-config ConfigMap with content hash abc was generated at 2024-01-02T03:04:05Z with the AWS transformer for platform AWS from openshift-config/cloud-provider-config ConfigMap resourceVersion 42",
+	}, {
+		name:    "not required",
+		gen:     &generation{platform: configv1.NonePlatformType, transformer: TransformerFor(configv1.NonePlatformType), managed: true},
+		status:  operatorv1.ConditionTrue,
+		reason:  "NotRequired",
+		message: "no kube-cloud-config is required with the None transformer for platform None",
+	}, {
+		name:    "not managed",
+		gen:     &generation{platform: configv1.VSpherePlatformType, transformer: vsphereTransformer{}},
+		status:  operatorv1.ConditionFalse,
+		reason:  "NotManaged",
+		message: "the kube-cloud-config of platform VSphere is managed by another operator",
+	}, {
+		name:   "failed reports the last generation",
+		gen:    &generation{platform: configv1.AWSPlatformType, transformer: awsTransformer{}, managed: true, source: source},
+		err:    errors.New("failed to read the cloud.conf"),
+		last:   last,
+		status: operatorv1.ConditionFalse,
+		reason: "GenerationFailed",
+		message: "generation with the AWS transformer for platform AWS from openshift-config/cloud-provider-config ConfigMap resourceVersion 42 failed: failed to read the cloud.conf; " +
+			"last generated: " + last,
+	}, {
+		name:    "failed without a last generation",
+		gen:     &generation{platform: configv1.AWSPlatformType, transformer: awsTransformer{}, managed: true},
+		err:     errors.New("configmaps \"cloud-provider-config\" not found"),
+		status:  operatorv1.ConditionFalse,
+		reason:  "GenerationFailed",
+		message: "generation with the AWS transformer for platform AWS failed: configmaps \"cloud-provider-config\" not found",
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			got := generatedCondition(test.gen, test.err, test.last)
+			assert.Equal(t, GeneratedConditionType, ptr.Deref(got.Type, ""))
+			assert.Equal(t, test.status, ptr.Deref(got.Status, ""))
+			assert.Equal(t, test.reason, ptr.Deref(got.Reason, ""))
+			assert.Equal(t, test.message, ptr.Deref(got.Message, ""))
+		})
+	}
+}
+
+func Test_sync_generatedCondition(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "test-region", ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "https://ec2.local"}}}}},
+	}
+	indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexerInfra.Add(infra))
+	kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config", ResourceVersion: "42"},
+		Data:       map[string]string{"config": "[Global]\nVPC = vpc-test\n"},
+	})
+	operatorClient := operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	clock := clocktesting.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	ctrl := KubeCloudConfigController{
+		operatorClient:        operatorClient,
+		infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+		configMapClient:       kubeClient.CoreV1(),
+		secretClient:          kubeClient.CoreV1(),
+		sourceConfigMapLister: configMapTrackerLister{kubeClient.Tracker(), "openshift-config"},
+		targetConfigMapLister: configMapTrackerLister{kubeClient.Tracker(), "openshift-config-managed"},
+		sourceSecretLister:    secretTrackerLister{kubeClient.Tracker(), "openshift-config"},
+		targetSecretLister:    secretTrackerLister{kubeClient.Tracker(), "openshift-config-managed"},
+		clock:                 clock,
+	}
+	syncCtx := factory.NewSyncContext("KubeCloudConfigController", events.NewInMemoryRecorder("KubeCloudConfigController", clocktesting.NewFakePassiveClock(time.Now())))
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+
+	// the generation is recorded in the annotations of the target
+	target, err := kubeClient.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "AWS", target.Annotations[TransformerAnnotation])
+	assert.Equal(t, "openshift-config/cloud-provider-config", target.Annotations[SourceAnnotation])
+	assert.Equal(t, "ConfigMap", target.Annotations[SourceKindAnnotation])
+	assert.Equal(t, "2024-01-02T03:04:05Z", target.Annotations[GeneratedAtAnnotation])
+	generated := "openshift-config-managed/kube-cloud-config ConfigMap with content hash " + target.Annotations[ContentHashAnnotation] +
+		" was generated at 2024-01-02T03:04:05Z with the AWS transformer for platform AWS from openshift-config/cloud-provider-config ConfigMap resourceVersion "
+	assert.Equal(t, generated+"42", generatedMessage(t, operatorClient))
+
+	// a metadata-only edit of the source is reported, but neither the target nor the time of the generation change
+	clock.Step(time.Minute)
+	source, err := kubeClient.CoreV1().ConfigMaps("openshift-config").Get(context.TODO(), "cloud-provider-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	source.ResourceVersion = "43"
+	source.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+	_, err = kubeClient.CoreV1().ConfigMaps("openshift-config").Update(context.TODO(), source, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	kubeClient.ClearActions()
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	assert.Equal(t, generated+"43", generatedMessage(t, operatorClient))
+	for _, a := range kubeClient.Actions() {
+		assert.Equalf(t, "get", a.GetVerb(), "unexpected %s of %s", a.GetVerb(), a.GetResource().Resource)
+	}
+
+	// the condition of an unchanged sync is unchanged, so that the operator status is not written
+	clock.Step(time.Minute)
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	assert.Equal(t, generated+"43", generatedMessage(t, operatorClient))
+
+	// a transformation error is reported along with the last generation
+	source.Data["config"] = "VPC = vpc-test\n"
+	source.ResourceVersion = "44"
+	_, err = kubeClient.CoreV1().ConfigMaps("openshift-config").Update(context.TODO(), source, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	assert.Error(t, ctrl.sync(context.TODO(), syncCtx))
+	_, status, _, err := operatorClient.GetOperatorState()
+	require.NoError(t, err)
+	failed := operatorv1helpers.FindOperatorCondition(status.Conditions, GeneratedConditionType)
+	require.NotNil(t, failed)
+	assert.Equal(t, operatorv1.ConditionFalse, failed.Status)
+	assert.Equal(t, "GenerationFailed", failed.Reason)
+	assert.Equal(t, "generation with the AWS transformer for platform AWS from openshift-config/cloud-provider-config ConfigMap resourceVersion 44 failed: "+
+		"failed to read the cloud.conf: 1:1: expected section header; last generated: openshift-config-managed/kube-cloud-config ConfigMap with content hash "+
+		target.Annotations[ContentHashAnnotation]+" was generated at 2024-01-02T03:04:05Z with the AWS transformer from openshift-config/cloud-provider-config ConfigMap",
+		failed.Message)
+}
+
+// generatedMessage returns the message of the GeneratedConditionType condition of operatorClient.
+func generatedMessage(t *testing.T, operatorClient operatorv1helpers.OperatorClient) string {
+	_, status, _, err := operatorClient.GetOperatorState()
+	require.NoError(t, err)
+	condition := operatorv1helpers.FindOperatorCondition(status.Conditions, GeneratedConditionType)
+	require.NotNil(t, condition)
+	assert.Equal(t, operatorv1.ConditionTrue, condition.Status)
+	return condition.Message
+}
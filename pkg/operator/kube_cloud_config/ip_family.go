@@ -0,0 +1,49 @@
+package kubecloudconfig
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var (
+	// nodeIPFamiliesByIPFamily maps the IP family of the platform status to the ordered node IP families of the cloud
+	// providers, primary family first.
+	nodeIPFamiliesByIPFamily = map[configv1.IPFamilyType][]string{
+		configv1.IPv4:                 {"ipv4"},
+		configv1.DualStackIPv4Primary: {"ipv4", "ipv6"},
+		configv1.DualStackIPv6Primary: {"ipv6", "ipv4"},
+	}
+
+	// defaultNodeIPFamilies are the node IP families of the cloud providers when none are configured.
+	defaultNodeIPFamilies = []string{"ipv4"}
+
+	validIPFamilyValues = []string{string(configv1.IPv4), string(configv1.DualStackIPv4Primary), string(configv1.DualStackIPv6Primary)}
+)
+
+// nodeIPFamilies returns the node IP families for ipFamily, or nil if it is unset.
+func nodeIPFamilies(ipFamily configv1.IPFamilyType, fldPath *field.Path) ([]string, error) {
+	if ipFamily == "" {
+		return nil, nil
+	}
+	families, ok := nodeIPFamiliesByIPFamily[ipFamily]
+	if !ok {
+		return nil, field.NotSupported(fldPath, ipFamily, validIPFamilyValues)
+	}
+	return families, nil
+}
+
+// equalIPFamilies reports whether the node IP families a and b are the same, in the same order.
+func equalIPFamilies(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
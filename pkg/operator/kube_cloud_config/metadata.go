@@ -0,0 +1,81 @@
+package kubecloudconfig
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PropagatedMetadataDomain is the domain of the labels and annotations propagated from the user-provided cloud config to
+// the kube-cloud-config. Keys with this domain, or one of its subdomains, as prefix are propagated, e.g.
+// `cloud-config.openshift.io/owner` or `vsphere.cloud-config.openshift.io/zone`. Other labels and annotations of the
+// user-provided cloud config, like those set by the tools managing it, are not.
+const PropagatedMetadataDomain = "cloud-config.openshift.io"
+
+// isPropagated returns true if the label or annotation key is propagated to the kube-cloud-config.
+func isPropagated(key string) bool {
+	prefix, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return false
+	}
+	return prefix == PropagatedMetadataDomain || strings.HasSuffix(prefix, "."+PropagatedMetadataDomain)
+}
+
+// propagated returns the entries of m with propagated keys, nil if there are none.
+func propagated(m map[string]string) map[string]string {
+	var ret map[string]string
+	for k, v := range m {
+		if !isPropagated(k) {
+			continue
+		}
+		if ret == nil {
+			ret = map[string]string{}
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
+// propagateMetadata copies the propagated labels and annotations of source to target.
+func propagateMetadata(source metav1.ObjectMeta, target *metav1.ObjectMeta) {
+	for k, v := range propagated(source.Labels) {
+		if target.Labels == nil {
+			target.Labels = map[string]string{}
+		}
+		target.Labels[k] = v
+	}
+	for k, v := range propagated(source.Annotations) {
+		if target.Annotations == nil {
+			target.Annotations = map[string]string{}
+		}
+		target.Annotations[k] = v
+	}
+}
+
+// equalPropagatedMetadata returns true if existing and target have the same propagated labels and annotations.
+func equalPropagatedMetadata(existing, target metav1.ObjectMeta) bool {
+	return equality.Semantic.DeepEqual(propagated(existing.Labels), propagated(target.Labels)) &&
+		equality.Semantic.DeepEqual(propagated(existing.Annotations), propagated(target.Annotations))
+}
+
+// removeStaleMetadata marks the propagated labels and annotations of existing that are no longer in target for removal,
+// using the `<key>-` convention of resourcemerge.MergeMap.
+func removeStaleMetadata(existing metav1.ObjectMeta, target *metav1.ObjectMeta) {
+	for k := range propagated(existing.Labels) {
+		if _, ok := target.Labels[k]; !ok {
+			if target.Labels == nil {
+				target.Labels = map[string]string{}
+			}
+			target.Labels[k+"-"] = ""
+		}
+	}
+	for k := range propagated(existing.Annotations) {
+		if _, ok := target.Annotations[k]; !ok {
+			if target.Annotations == nil {
+				target.Annotations = map[string]string{}
+			}
+			target.Annotations[k+"-"] = ""
+		}
+	}
+}
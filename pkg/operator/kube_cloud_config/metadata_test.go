@@ -0,0 +1,133 @@
+package kubecloudconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func Test_isPropagated(t *testing.T) {
+	for key, expected := range map[string]bool{
+		"cloud-config.openshift.io/owner":                  true,
+		"vsphere.cloud-config.openshift.io/zone":           true,
+		"cloud-config.openshift.io":                        false,
+		"owner":                                            false,
+		"kube-cloud-config.config.openshift.io/x":          false,
+		"mycloud-config.openshift.io/owner":                false,
+		"cloud-config.openshift.io.example.com/x":          false,
+		ContentHashAnnotation:                              false,
+		"kubectl.kubernetes.io/last-applied-configuration": false,
+	} {
+		assert.Equalf(t, expected, isPropagated(key), "isPropagated(%q)", key)
+	}
+}
+
+func Test_sync_propagatesMetadata(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
+	}
+	indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexerInfra.Add(infra))
+	kubeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cloud-provider-config",
+			Namespace:       "openshift-config",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"cloud-config.openshift.io/tier": "gold", "app": "installer"},
+			Annotations: map[string]string{
+				"vsphere.cloud-config.openshift.io/zone":           "a",
+				"cloud-config.openshift.io/owner":                  "team-a",
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		Data: map[string]string{"config": "[Global]\nVPC = vpc-test\n", "ca-bundle.pem": "bundle"},
+	})
+	ctrl := KubeCloudConfigController{
+		operatorClient:        operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+		infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+		configMapClient:       kubeClient.CoreV1(),
+		secretClient:          kubeClient.CoreV1(),
+		sourceConfigMapLister: configMapTrackerLister{kubeClient.Tracker(), "openshift-config"},
+		targetConfigMapLister: configMapTrackerLister{kubeClient.Tracker(), "openshift-config-managed"},
+		sourceSecretLister:    secretTrackerLister{kubeClient.Tracker(), "openshift-config"},
+		targetSecretLister:    secretTrackerLister{kubeClient.Tracker(), "openshift-config-managed"},
+		clock:                 clocktesting.NewFakePassiveClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+	syncCtx := factory.NewSyncContext("KubeCloudConfigController", events.NewInMemoryRecorder("KubeCloudConfigController", clocktesting.NewFakePassiveClock(time.Now())))
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+
+	target, err := kubeClient.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cloud-config.openshift.io/tier": "gold"}, target.Labels)
+	assert.Equal(t, map[string]string{
+		"vsphere.cloud-config.openshift.io/zone": "a",
+		"cloud-config.openshift.io/owner":        "team-a",
+		ContentHashAnnotation:                    contentHash(target),
+		TransformerAnnotation:                    "AWS",
+		SourceAnnotation:                         "openshift-config/cloud-provider-config",
+		SourceKindAnnotation:                     "ConfigMap",
+		GeneratedAtAnnotation:                    "2024-01-02T03:04:05Z",
+	}, target.Annotations)
+	assert.Equal(t, map[string]string{"cloud.conf": "[Global]\nVPC = vpc-test\n", "ca-bundle.pem": "bundle"}, target.Data)
+
+	// a change of the propagated metadata only is written, removed keys are removed from the target
+	source, err := kubeClient.CoreV1().ConfigMaps("openshift-config").Get(context.TODO(), "cloud-provider-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	delete(source.Annotations, "vsphere.cloud-config.openshift.io/zone")
+	source.Annotations["cloud-config.openshift.io/owner"] = "team-b"
+	source.ResourceVersion = "2"
+	_, err = kubeClient.CoreV1().ConfigMaps("openshift-config").Update(context.TODO(), source, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+
+	target, err = kubeClient.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"cloud-config.openshift.io/owner": "team-b",
+		ContentHashAnnotation:             contentHash(target),
+		TransformerAnnotation:             "AWS",
+		SourceAnnotation:                  "openshift-config/cloud-provider-config",
+		SourceKindAnnotation:              "ConfigMap",
+		GeneratedAtAnnotation:             "2024-01-02T03:04:05Z",
+	}, target.Annotations)
+}
+
+func Test_bootstrapTarget_propagatesMetadata(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		Spec:   configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}},
+		Status: configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType}},
+	}
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "cloud-provider-config",
+			Namespace:       "openshift-config",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"cloud-config.openshift.io/tier": "gold", "app": "installer"},
+		},
+		Data: map[string]string{"config": "[global]\n"},
+	}
+	target, err := bootstrapTarget(infra, source)
+	require.NoError(t, err)
+	assert.Equal(t, metav1.ObjectMeta{
+		Name:        "kube-cloud-config",
+		Namespace:   "openshift-config-managed",
+		Labels:      map[string]string{"cloud-config.openshift.io/tier": "gold"},
+		Annotations: map[string]string{ContentHashAnnotation: contentHash(target)},
+	}, target.ObjectMeta)
+}
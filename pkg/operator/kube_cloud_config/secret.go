@@ -0,0 +1,109 @@
+package kubecloudconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	configMapKind = "ConfigMap"
+	secretKind    = "Secret"
+)
+
+var validKinds = []string{configMapKind, secretKind}
+
+// objectKinds returns the kinds of the source and target of the kube-cloud-config selected by overrides. A Secret
+// source requires a Secret target.
+func objectKinds(overrides operatorclient.KubeCloudConfigOverrides) (string, string, error) {
+	fldPath := field.NewPath("spec", "unsupportedConfigOverrides", "kubeCloudConfig")
+	sourceKind, targetKind := configMapKind, configMapKind
+	switch overrides.SourceKind {
+	case "", configMapKind:
+	case secretKind:
+		sourceKind = secretKind
+	default:
+		return "", "", field.NotSupported(fldPath.Child("sourceKind"), overrides.SourceKind, validKinds)
+	}
+	switch overrides.TargetKind {
+	case "", configMapKind:
+	case secretKind:
+		targetKind = secretKind
+	default:
+		return "", "", field.NotSupported(fldPath.Child("targetKind"), overrides.TargetKind, validKinds)
+	}
+	// the cloud.conf of a Secret must not be copied to a ConfigMap readable by everyone
+	if sourceKind == secretKind && targetKind != secretKind {
+		return "", "", field.Invalid(fldPath.Child("targetKind"), targetKind, "must be Secret when sourceKind is Secret")
+	}
+	return sourceKind, targetKind, nil
+}
+
+// SecretsRequired returns true if overrides select a Secret source or target. The Secrets of openshift-config and
+// openshift-config-managed are only watched then, see NewController.
+func SecretsRequired(overrides operatorclient.KubeCloudConfigOverrides) bool {
+	return overrides.SourceKind == secretKind || overrides.TargetKind == secretKind
+}
+
+// configMapFromSecret returns a ConfigMap with the data of secret, so that it can be transformed like a
+// user-provided ConfigMap. Values that are not valid UTF-8 are stored as binary data.
+func configMapFromSecret(secret *corev1.Secret) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{}
+	for k, v := range secret.Data {
+		if utf8.Valid(v) {
+			if cm.Data == nil {
+				cm.Data = map[string]string{}
+			}
+			cm.Data[k] = string(v)
+			continue
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = map[string][]byte{}
+		}
+		cm.BinaryData[k] = v
+	}
+	return cm
+}
+
+// secretFromConfigMap returns the Secret holding the data and binary data of the transformed target, with the
+// content hash of the Secret recorded in its ContentHashAnnotation.
+func secretFromConfigMap(target *corev1.ConfigMap) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: *target.ObjectMeta.DeepCopy(),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       make(map[string][]byte, len(target.Data)+len(target.BinaryData)),
+	}
+	for k, v := range target.Data {
+		secret.Data[k] = []byte(v)
+	}
+	for k, v := range target.BinaryData {
+		secret.Data[k] = v
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[ContentHashAnnotation] = secretContentHash(secret)
+	return secret
+}
+
+// secretContentHash returns a hash of the data of secret, independent of the order of the keys.
+func secretContentHash(secret *corev1.Secret) string {
+	h := sha256.New()
+	for _, k := range sortedKeys(secret.Data) {
+		fmt.Fprintf(h, "data/%q=%q\n", k, secret.Data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// secretUpToDate returns true if existing has the content, the propagated metadata and the generation annotations of
+// target, and was not modified since it was written, see upToDate.
+func secretUpToDate(existing, target *corev1.Secret, equalConfigs func(a, b []byte) bool) bool {
+	return existing.Type == target.Type && existing.Annotations[ContentHashAnnotation] == secretContentHash(existing) &&
+		equalData(existing.Data, target.Data, equalConfigs) &&
+		equalPropagatedMetadata(existing.ObjectMeta, target.ObjectMeta) && equalGenerationAnnotations(existing.ObjectMeta, target.ObjectMeta)
+}
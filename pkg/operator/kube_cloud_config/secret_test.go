@@ -0,0 +1,146 @@
+package kubecloudconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func Test_sync_secrets(t *testing.T) {
+	const cloudConf = "[Global]\nVPC = vpc-test\n"
+
+	cases := []struct {
+		name      string
+		overrides string
+		objects   []runtime.Object
+		// unwatched leaves the Secret listers unset, as when Secrets are not selected at startup
+		unwatched bool
+
+		configMapData map[string]string
+		secretData    map[string][]byte
+		err           string
+	}{{
+		name:      "Secret source to ConfigMap target is rejected",
+		overrides: `{"kubeCloudConfig":{"sourceKind":"Secret"}}`,
+		objects: []runtime.Object{
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string][]byte{"config": []byte(cloudConf)}},
+		},
+		err: `spec.unsupportedConfigOverrides.kubeCloudConfig.targetKind: Invalid value: "ConfigMap": must be Secret when sourceKind is Secret`,
+	}, {
+		name:      "ConfigMap source to Secret target deletes the ConfigMap target",
+		overrides: `{"kubeCloudConfig":{"targetKind":"Secret"}}`,
+		objects: []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string]string{"config": cloudConf}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed"}, Data: map[string]string{"cloud.conf": cloudConf}},
+		},
+		secretData: map[string][]byte{"cloud.conf": []byte(cloudConf)},
+	}, {
+		name:      "Secret source to Secret target keeps binary data and deletes the ConfigMap target",
+		overrides: `{"kubeCloudConfig":{"sourceKind":"Secret","targetKind":"Secret"}}`,
+		objects: []runtime.Object{
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string][]byte{"config": []byte(cloudConf), "key.bin": {0xff, 0xfe}}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed"}, Data: map[string]string{"cloud.conf": "[Global]\n"}},
+		},
+		secretData: map[string][]byte{"cloud.conf": []byte(cloudConf), "key.bin": {0xff, 0xfe}},
+	}, {
+		name: "ConfigMap target replaces the Secret target",
+		objects: []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string]string{"config": cloudConf}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed"}, Data: map[string][]byte{"cloud.conf": []byte(cloudConf)}},
+		},
+		configMapData: map[string]string{"cloud.conf": cloudConf},
+	}, {
+		name:      "ConfigMap target without watched Secrets",
+		unwatched: true,
+		objects: []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string]string{"config": cloudConf}},
+		},
+		configMapData: map[string]string{"cloud.conf": cloudConf},
+	}, {
+		name:      "Secret target without watched Secrets is reported",
+		overrides: `{"kubeCloudConfig":{"targetKind":"Secret"}}`,
+		unwatched: true,
+		objects: []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string]string{"config": cloudConf}},
+		},
+		err: `openshift-config/openshift-config-managed Secrets are selected but not watched, restart the operator to watch them`,
+	}, {
+		name:      "missing Secret source",
+		overrides: `{"kubeCloudConfig":{"sourceKind":"Secret","targetKind":"Secret"}}`,
+		objects: []runtime.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"}, Data: map[string]string{"config": cloudConf}},
+		},
+		err: `secrets "cloud-provider-config" not found`,
+	}, {
+		name:      "unsupported kind",
+		overrides: `{"kubeCloudConfig":{"targetKind":"Vault"}}`,
+		err:       `spec.unsupportedConfigOverrides.kubeCloudConfig.targetKind: Unsupported value: "Vault": supported values: "ConfigMap", "Secret"`,
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.InfrastructureSpec{CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"}},
+				Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
+			}
+			indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			assert.NoError(t, indexerInfra.Add(infra))
+			kubeClient := fake.NewSimpleClientset(test.objects...)
+			spec := &operatorv1.OperatorSpec{}
+			spec.UnsupportedConfigOverrides.Raw = []byte(test.overrides)
+
+			ctrl := KubeCloudConfigController{
+				operatorClient:        operatorv1helpers.NewFakeOperatorClient(spec, &operatorv1.OperatorStatus{}, nil),
+				infraLister:           configv1listers.NewInfrastructureLister(indexerInfra),
+				configMapClient:       kubeClient.CoreV1(),
+				secretClient:          kubeClient.CoreV1(),
+				sourceConfigMapLister: configMapTrackerLister{kubeClient.Tracker(), "openshift-config"},
+				targetConfigMapLister: configMapTrackerLister{kubeClient.Tracker(), "openshift-config-managed"},
+				sourceSecretLister:    secretTrackerLister{kubeClient.Tracker(), "openshift-config"},
+				targetSecretLister:    secretTrackerLister{kubeClient.Tracker(), "openshift-config-managed"},
+				clock:                 clocktesting.NewFakePassiveClock(time.Now()),
+			}
+			if test.unwatched {
+				ctrl.sourceSecretLister, ctrl.targetSecretLister = nil, nil
+			}
+			err := ctrl.sync(context.TODO(),
+				factory.NewSyncContext("KubeCloudConfigController", events.NewInMemoryRecorder("KubeCloudConfigController", clocktesting.NewFakePassiveClock(time.Now()))))
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			configMap, err := kubeClient.CoreV1().ConfigMaps("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+			if test.configMapData == nil {
+				assert.True(t, apierrors.IsNotFound(err), "unexpected ConfigMap target: %v", err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, test.configMapData, configMap.Data)
+			}
+
+			secret, err := kubeClient.CoreV1().Secrets("openshift-config-managed").Get(context.TODO(), "kube-cloud-config", metav1.GetOptions{})
+			if test.secretData == nil {
+				assert.True(t, apierrors.IsNotFound(err), "unexpected Secret target: %v", err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, test.secretData, secret.Data)
+				assert.Equal(t, corev1.SecretTypeOpaque, secret.Type)
+				assert.Equal(t, secretContentHash(secret), secret.Annotations[ContentHashAnnotation])
+			}
+		})
+	}
+}
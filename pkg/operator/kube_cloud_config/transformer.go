@@ -0,0 +1,91 @@
+package kubecloudconfig
+
+import (
+	"bytes"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Transformer produces the kube-cloud-config of one platform from the user-provided cloud config and the
+// infrastructures.config.openshift.io object.
+// A platform is added by implementing a Transformer in its own file and registering it with RegisterTransformer.
+type Transformer interface {
+	// Platform returns the platform type the Transformer is registered for.
+	Platform() configv1.PlatformType
+
+	// Owns returns whether the KubeCloudConfigController manages the kube-cloud-config of the platform with the given
+	// feature gates. When it returns false, the kube-cloud-config is managed by another operator and is left alone.
+	// A nil featureGates is treated as if no feature gates are enabled.
+	Owns(featureGates featuregates.FeatureGateAccess) bool
+
+	// Validate returns an error if the infrastructure object cannot be transformed for the platform.
+	Validate(infra *configv1.Infrastructure) error
+
+	// Transform transforms the input ConfigMap, holding the user-provided cloud config at key, using the infrastructure
+	// object. Only the data and binaryData fields of the output ConfigMap are respected. Transform is only called with
+	// an infrastructure object that passed Validate.
+	Transform(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error)
+}
+
+// ConfigComparer is implemented by the Transformers that can compare the cloud.conf of their platform semantically.
+// The kube-cloud-config is not written when its cloud.conf only differs from the generated one in ways the cloud
+// provider ignores, like the order of keys and whitespace, so that such edits of the source do not roll out.
+type ConfigComparer interface {
+	// EqualConfigs returns true if the cloud.conf a and b configure the cloud provider the same way.
+	EqualConfigs(a, b []byte) bool
+}
+
+var transformers = map[configv1.PlatformType]Transformer{}
+
+// RegisterTransformer adds the Transformer for its platform.
+// It panics if a Transformer is already registered for the platform.
+func RegisterTransformer(transformer Transformer) {
+	platform := transformer.Platform()
+	if _, ok := transformers[platform]; ok {
+		panic(fmt.Sprintf("kube-cloud-config transformer for platform %q registered twice", platform))
+	}
+	transformers[platform] = transformer
+}
+
+// TransformerFor returns the Transformer registered for platform. Platforms without a registered Transformer get
+// one that owns the kube-cloud-config and copies the user-provided cloud config as-is.
+func TransformerFor(platform configv1.PlatformType) Transformer {
+	if transformer, ok := transformers[platform]; ok {
+		return transformer
+	}
+	return defaultTransformer{platform: platform}
+}
+
+// equalConfigsFunc returns the comparison of the cloud.conf of transformer, byte-wise if it is not a ConfigComparer.
+func equalConfigsFunc(transformer Transformer) func(a, b []byte) bool {
+	if comparer, ok := transformer.(ConfigComparer); ok {
+		return comparer.EqualConfigs
+	}
+	return bytes.Equal
+}
+
+// transform validates the infrastructure object and transforms the input ConfigMap with transformer.
+func transform(transformer Transformer, input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
+	if err := transformer.Validate(infra); err != nil {
+		return nil, err
+	}
+	return transformer.Transform(input, key, infra)
+}
+
+// defaultTransformer is the Transformer of the platforms without a registered Transformer.
+type defaultTransformer struct {
+	platform configv1.PlatformType
+}
+
+func (t defaultTransformer) Platform() configv1.PlatformType { return t.platform }
+
+func (defaultTransformer) Owns(featuregates.FeatureGateAccess) bool { return true }
+
+func (defaultTransformer) Validate(*configv1.Infrastructure) error { return nil }
+
+func (defaultTransformer) Transform(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
+	return asIsTransformer(input, key, infra)
+}
@@ -0,0 +1,86 @@
+package kubecloudconfig
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_TransformerFor(t *testing.T) {
+	for _, platform := range []configv1.PlatformType{configv1.AWSPlatformType, configv1.AzurePlatformType, configv1.VSpherePlatformType} {
+		transformer := TransformerFor(platform)
+		assert.Equal(t, platform, transformer.Platform())
+		assert.NotEqual(t, defaultTransformer{platform: platform}, transformer, "no transformer registered for %s", platform)
+	}
+
+	// platforms without a registered transformer get the default one
+	for _, platform := range []configv1.PlatformType{configv1.GCPPlatformType, configv1.NonePlatformType, "Unknown"} {
+		transformer := TransformerFor(platform)
+		assert.Equal(t, defaultTransformer{platform: platform}, transformer)
+		assert.Equal(t, platform, transformer.Platform())
+		assert.True(t, transformer.Owns(nil))
+	}
+}
+
+func Test_RegisterTransformer(t *testing.T) {
+	assert.PanicsWithValue(t, `kube-cloud-config transformer for platform "AWS" registered twice`, func() {
+		RegisterTransformer(awsTransformer{})
+	})
+}
+
+func Test_vsphereTransformer_Owns(t *testing.T) {
+	enabled := featuregates.NewHardcodedFeatureGateAccess([]configv1.FeatureGateName{features.FeatureGateVSphereMultiVCenterDay2}, nil)
+	disabled := featuregates.NewHardcodedFeatureGateAccess(nil, []configv1.FeatureGateName{features.FeatureGateVSphereMultiVCenterDay2})
+
+	transformer := TransformerFor(configv1.VSpherePlatformType)
+	assert.False(t, transformer.Owns(enabled))
+	assert.True(t, transformer.Owns(disabled))
+	assert.True(t, transformer.Owns(nil))
+}
+
+func Test_Transformer_keepsExtraKeys(t *testing.T) {
+	cases := []struct {
+		cloudConf string
+		status    configv1.PlatformStatus
+	}{{
+		cloudConf: "[Global]\nVPC = vpc-test\n",
+		status: configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{
+			Region:           "us-east-1",
+			ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "https://ec2.local"}},
+			IPFamily:         configv1.DualStackIPv4Primary,
+		}},
+	}, {
+		cloudConf: `{"resourceGroup":"rg"}`,
+		status: configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{
+			CloudName:   configv1.AzureStackCloud,
+			ARMEndpoint: "https://management.region.example.com",
+		}},
+	}, {
+		cloudConf: "[Global]\n",
+		status:    configv1.PlatformStatus{Type: configv1.VSpherePlatformType},
+	}, {
+		cloudConf: "[global]\n",
+		status:    configv1.PlatformStatus{Type: configv1.GCPPlatformType},
+	}}
+	for _, test := range cases {
+		t.Run(string(test.status.Type), func(t *testing.T) {
+			input := &corev1.ConfigMap{
+				Data:       map[string]string{"config": test.cloudConf, "ca-bundle.pem": "bundle"},
+				BinaryData: map[string][]byte{"ca.der": {0x30, 0x82}},
+			}
+			infra := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: &test.status}}
+
+			output, err := transform(TransformerFor(test.status.Type), input, "config", infra)
+			require.NoError(t, err)
+			assert.Equal(t, "bundle", output.Data["ca-bundle.pem"])
+			assert.Equal(t, []byte{0x30, 0x82}, output.BinaryData["ca.der"])
+			assert.NotContains(t, output.Data, "config")
+			assert.Contains(t, output.Data, "cloud.conf")
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package kubecloudconfig
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func init() {
+	RegisterTransformer(vsphereTransformer{})
+}
+
+// vsphereTransformer implements the Transformer for vSphere. The user-provided cloud config is copied as-is.
+type vsphereTransformer struct{}
+
+func (vsphereTransformer) Platform() configv1.PlatformType { return configv1.VSpherePlatformType }
+
+// Owns returns false when the VSphereMultiVCenterDay2 feature gate is enabled, in which case the kube-cloud-config
+// is managed by the cluster-cloud-controller-manager-operator.
+func (vsphereTransformer) Owns(featureGates featuregates.FeatureGateAccess) bool {
+	return !isFeatureGateEnabled(featureGates, features.FeatureGateVSphereMultiVCenterDay2)
+}
+
+func (vsphereTransformer) Validate(*configv1.Infrastructure) error { return nil }
+
+func (vsphereTransformer) Transform(input *corev1.ConfigMap, key string, infra *configv1.Infrastructure) (*corev1.ConfigMap, error) {
+	return asIsTransformer(input, key, infra)
+}
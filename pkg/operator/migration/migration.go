@@ -0,0 +1,121 @@
+// Package migration applies the changes of the migration controllers. Migrated objects are stamped with an
+// AnnotationPrefix annotation recording when the migration was applied, migrations of a status are only recorded in
+// the <controller>MigrationPending condition. With spec.unsupportedConfigOverrides.migration.dryRun set on the operator
+// Config, pending changes are only reported as events and in that condition.
+package migration
+
+import (
+	"context"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+)
+
+// AnnotationPrefix prefixes the annotation recording, per controller, when a migration was last applied to an object.
+const AnnotationPrefix = "migration.config.openshift.io/"
+
+// Change is a pending migration of a cluster-scoped config object.
+type Change struct {
+	// Description is a human readable summary of the change, used in events and conditions.
+	Description string
+	// Apply writes the change. The annotations are set on the migrated object in the same write to leave an audit
+	// record, unless the change is written to a subresource that drops metadata, like status.
+	Apply func(ctx context.Context, annotations map[string]string) error
+}
+
+// Migrator applies the changes of a migration controller, or only reports them when
+// spec.unsupportedConfigOverrides.migration.dryRun is set on the operator Config.
+// The state of the migration is reported in the <controller>MigrationPending condition.
+type Migrator struct {
+	controllerName string
+	operatorClient operatorv1helpers.OperatorClient
+	clock          clock.PassiveClock
+}
+
+// NewMigrator returns a Migrator for the named controller.
+func NewMigrator(controllerName string, operatorClient operatorv1helpers.OperatorClient, clock clock.PassiveClock) *Migrator {
+	return &Migrator{
+		controllerName: controllerName,
+		operatorClient: operatorClient,
+		clock:          clock,
+	}
+}
+
+// Sync applies change, or records it as pending in dry-run. A nil change means that there is nothing to migrate.
+// It returns whether change was written.
+func (m *Migrator) Sync(ctx context.Context, recorder events.Recorder, change *Change) (bool, error) {
+	spec, status, _, err := m.operatorClient.GetOperatorState()
+	if err != nil {
+		return false, err
+	}
+	overrides, err := operatorclient.GetUnsupportedConfigOverrides(spec)
+	if err != nil {
+		return false, err
+	}
+
+	conditionType := m.controllerName + "MigrationPending"
+	condition := applyoperatorv1.OperatorCondition().WithType(conditionType)
+	switch {
+	case change == nil:
+		condition = condition.
+			WithStatus(operatorv1.ConditionFalse).
+			WithReason("AsExpected")
+
+	case overrides.Migration.DryRun:
+		// only report a pending change once, dry-run is evaluated on every resync
+		if existing := operatorv1helpers.FindOperatorCondition(status.Conditions, conditionType); existing == nil ||
+			existing.Status != operatorv1.ConditionTrue || existing.Message != change.Description {
+			recorder.Eventf("MigrationDryRun", "%s would migrate: %s", m.controllerName, change.Description)
+		}
+		condition = condition.
+			WithStatus(operatorv1.ConditionTrue).
+			WithReason("DryRun").
+			WithMessage(change.Description)
+
+	default:
+		annotations := map[string]string{
+			AnnotationPrefix + m.controllerName: m.clock.Now().UTC().Format(time.RFC3339),
+		}
+		if err := change.Apply(ctx, annotations); err != nil {
+			return false, err
+		}
+		recorder.Eventf("MigrationApplied", "%s migrated: %s", m.controllerName, change.Description)
+		condition = condition.
+			WithStatus(operatorv1.ConditionFalse).
+			WithReason("Applied").
+			WithMessage(change.Description)
+	}
+
+	return change != nil && !overrides.Migration.DryRun, m.applyCondition(ctx, status, condition)
+}
+
+// Inactive records that the migration does not apply to this cluster, e.g. because it expired.
+func (m *Migrator) Inactive(ctx context.Context, reason, message string) error {
+	_, status, _, err := m.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+	return m.applyCondition(ctx, status, applyoperatorv1.OperatorCondition().
+		WithType(m.controllerName+"MigrationPending").
+		WithStatus(operatorv1.ConditionFalse).
+		WithReason(reason).
+		WithMessage(message))
+}
+
+// applyCondition applies condition unless status already holds it, migrations are evaluated on every resync.
+func (m *Migrator) applyCondition(ctx context.Context, status *operatorv1.OperatorStatus, condition *applyoperatorv1.OperatorConditionApplyConfiguration) error {
+	if existing := operatorv1helpers.FindOperatorCondition(status.Conditions, *condition.Type); existing != nil &&
+		existing.Status == *condition.Status && existing.Reason == ptr.Deref(condition.Reason, "") && existing.Message == ptr.Deref(condition.Message, "") {
+		return nil
+	}
+	return m.operatorClient.ApplyOperatorStatus(ctx,
+		factory.ControllerFieldManager(m.controllerName, "migration"),
+		applyoperatorv1.OperatorStatus().WithConditions(condition))
+}
@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// countingOperatorClient counts the status applies.
+type countingOperatorClient struct {
+	operatorv1helpers.OperatorClient
+	applies int
+}
+
+func (c *countingOperatorClient) ApplyOperatorStatus(ctx context.Context, fieldManager string, applyConfiguration *applyoperatorv1.OperatorStatusApplyConfiguration) error {
+	c.applies++
+	return c.OperatorClient.ApplyOperatorStatus(ctx, fieldManager, applyConfiguration)
+}
+
+func TestMigrator_Sync(t *testing.T) {
+	cases := []struct {
+		name       string
+		overrides  string
+		change     bool
+		conditions []operatorv1.OperatorCondition
+
+		applied         bool
+		events          []string
+		statusApplied   bool
+		conditionStatus operatorv1.ConditionStatus
+		conditionReason string
+		err             string
+	}{{
+		name:            "nothing to migrate",
+		statusApplied:   true,
+		conditionStatus: operatorv1.ConditionFalse,
+		conditionReason: "AsExpected",
+	}, {
+		name: "nothing to migrate already reported",
+		conditions: []operatorv1.OperatorCondition{{
+			Type: "TestControllerMigrationPending", Status: operatorv1.ConditionFalse, Reason: "AsExpected",
+		}},
+		conditionStatus: operatorv1.ConditionFalse,
+		conditionReason: "AsExpected",
+	}, {
+		name:            "apply",
+		change:          true,
+		applied:         true,
+		events:          []string{"MigrationApplied"},
+		statusApplied:   true,
+		conditionStatus: operatorv1.ConditionFalse,
+		conditionReason: "Applied",
+	}, {
+		name:            "dry run",
+		overrides:       `{"migration":{"dryRun":true}}`,
+		change:          true,
+		events:          []string{"MigrationDryRun"},
+		statusApplied:   true,
+		conditionStatus: operatorv1.ConditionTrue,
+		conditionReason: "DryRun",
+	}, {
+		name:      "dry run already reported",
+		overrides: `{"migration":{"dryRun":true}}`,
+		change:    true,
+		conditions: []operatorv1.OperatorCondition{{
+			Type: "TestControllerMigrationPending", Status: operatorv1.ConditionTrue, Reason: "DryRun", Message: "set foo",
+		}},
+		conditionStatus: operatorv1.ConditionTrue,
+		conditionReason: "DryRun",
+	}, {
+		name:      "invalid overrides",
+		overrides: `{"migration":{"dryRun":"maybe"}}`,
+		change:    true,
+		err:       "unable to parse spec.unsupportedConfigOverrides",
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			spec := &operatorv1.OperatorSpec{UnsupportedConfigOverrides: runtime.RawExtension{Raw: []byte(test.overrides)}}
+			operatorClient := &countingOperatorClient{
+				OperatorClient: operatorv1helpers.NewFakeOperatorClient(spec, &operatorv1.OperatorStatus{Conditions: test.conditions}, nil),
+			}
+			fakeClock := clocktesting.NewFakePassiveClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+			recorder := events.NewInMemoryRecorder("test", fakeClock)
+
+			var applied map[string]string
+			var change *Change
+			if test.change {
+				change = &Change{
+					Description: "set foo",
+					Apply: func(_ context.Context, annotations map[string]string) error {
+						applied = annotations
+						return nil
+					},
+				}
+			}
+
+			written, err := NewMigrator("TestController", operatorClient, fakeClock).Sync(context.TODO(), recorder, change)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.applied, written)
+			assert.Equal(t, test.statusApplied, operatorClient.applies > 0)
+
+			if test.applied {
+				assert.Equal(t, map[string]string{"migration.config.openshift.io/TestController": "2024-01-02T03:04:05Z"}, applied)
+			} else {
+				assert.Nil(t, applied)
+			}
+
+			var reasons []string
+			for _, e := range recorder.Events() {
+				reasons = append(reasons, e.Reason)
+			}
+			assert.Equal(t, test.events, reasons)
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			require.NoError(t, err)
+			condition := operatorv1helpers.FindOperatorCondition(status.Conditions, "TestControllerMigrationPending")
+			require.NotNil(t, condition)
+			assert.Equal(t, test.conditionStatus, condition.Status)
+			assert.Equal(t, test.conditionReason, condition.Reason)
+		})
+	}
+}
@@ -2,12 +2,18 @@ package migration_platform_status
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
+	applyconfigv1 "github.com/openshift/client-go/config/applyconfigurations/config/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
 	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
+	"github.com/openshift/cluster-config-operator/pkg/operator/infrastructurestatus"
+	"github.com/openshift/cluster-config-operator/pkg/operator/migration"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -16,10 +22,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/yaml"
 )
 
 const (
+	fieldManager = "MigrationPlatformStatusController"
+
 	clusterConfigNamespace = "kube-system"
 	clusterConfigName      = "cluster-config-v1"
 	clusterConfigKey       = "install-config"
@@ -29,12 +39,21 @@ const (
 // to include the `PlatformStatus` based on the InstallConfig stored in the ConfigMap `kube-system/cluster-config-v1`.
 // BZ: https://bugzilla.redhat.com/show_bug.cgi?id=1814332
 // The controller reads the configmap for the `install-config.yaml` and then creates a `PlatformStatus` and updates the infrastructure object with these values.
+// The status is written with server-side apply, owning only the fields that were backfilled.
 //
-// It uses the `.status.platformType` from infrastructure object to identify the platform.
+// It uses the `.status.platformStatus.type` from infrastructure object to identify the platform, which is set by the
+// InfrastructureNormalizerController.
+// The AWS region is required, while the other platform specific fields (GCP project and region, Azure cloud and
+// resource group, IBMCloud and PowerVS location, vSphere and OpenStack VIPs) are backfilled when available.
 type MigrationPlatformStatusController struct {
 	infraClient     configv1client.InfrastructureInterface
 	infraLister     configv1listers.InfrastructureLister
 	configMapClient corev1client.ConfigMapsGetter
+	migrator        *migration.Migrator
+
+	// reportedMissing are the install-config fields reported missing by the last sync, an event is only emitted when
+	// they change. The controller syncs with a single worker.
+	reportedMissing string
 }
 
 // NewController returns a MigrationPlatformStatusController
@@ -42,11 +61,13 @@ func NewController(operatorClient operatorv1helpers.OperatorClient,
 	infraClient configv1client.InfrastructuresGetter, infraLister configv1listers.InfrastructureLister, infraInformer cache.SharedIndexInformer,
 	configMapClient corev1client.ConfigMapsGetter,
 	kubeSystemInformer cache.SharedIndexInformer,
+	clock clock.PassiveClock,
 	recorder events.Recorder) factory.Controller {
 	c := &MigrationPlatformStatusController{
 		infraClient:     infraClient.Infrastructures(),
 		infraLister:     infraLister,
 		configMapClient: configMapClient,
+		migrator:        migration.NewMigrator("MigrationPlatformStatusController", operatorClient, clock),
 	}
 	return factory.New().
 		WithInformers(
@@ -54,13 +75,13 @@ func NewController(operatorClient operatorv1helpers.OperatorClient,
 			infraInformer,
 			kubeSystemInformer,
 		).
-		WithSync(c.sync).
+		WithSync(controllergate.Guard("MigrationPlatformStatusController", operatorClient, controllergate.Managed(operatorClient, c.sync, nil))).
 		WithSyncDegradedOnError(operatorClient).
 		ResyncEvery(time.Minute).
 		ToController("MigrationPlatformStatusController", recorder)
 }
 
-func (c MigrationPlatformStatusController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+func (c *MigrationPlatformStatusController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
 	obji, err := c.infraLister.Get("cluster")
 	if errors.IsNotFound(err) {
 		syncCtx.Recorder().Warningf("MigrationPlatformStatusController", "Required infrastructures.%s/cluster not found", configv1.GroupName)
@@ -72,42 +93,127 @@ func (c MigrationPlatformStatusController) sync(ctx context.Context, syncCtx fac
 
 	currentInfra := obji.DeepCopy()
 
-	if currentInfra.Status.PlatformStatus == nil {
-		currentInfra.Status.PlatformStatus = &configv1.PlatformStatus{}
-	}
-	if currentInfra.Status.PlatformStatus.Type == "" {
-		currentInfra.Status.PlatformStatus.Type = currentInfra.Status.Platform
-	}
-	if old, new := currentInfra.Status.Platform, currentInfra.Status.PlatformStatus.Type; old != "" && new != "" && old != new {
-		message := fmt.Sprintf("Mis-match between status.platform (%s) and status.platformStatus.type (%s) in infrastructures.%s/cluster", old, new, configv1.GroupName)
-		syncCtx.Recorder().Warningf("MigrationPlatformStatusController", message)
-		return fmt.Errorf("%s", message)
+	if currentInfra.Status.PlatformStatus == nil || currentInfra.Status.PlatformStatus.Type == "" {
+		// the InfrastructureNormalizerController sets status.platformStatus.type first
+		klog.V(4).Infof("MigrationPlatformStatusController: waiting for infrastructures.%s/cluster status.platformStatus.type", configv1.GroupName)
+		return nil
 	}
 
-	if err := c.migratePlatformSpecificFields(ctx, currentInfra); err != nil {
+	if err := c.migratePlatformSpecificFields(ctx, syncCtx.Recorder(), currentInfra); err != nil {
 		syncCtx.Recorder().Warningf("MigrationPlatformStatusController", err.Error())
 		return err
 	}
 
 	if equality.Semantic.DeepEqual(obji.Status.PlatformStatus, currentInfra.Status.PlatformStatus) {
 		// no changes made to platform status
-		return nil
+		_, err := c.migrator.Sync(ctx, syncCtx.Recorder(), nil)
+		return err
 	}
 
-	_, err = c.infraClient.UpdateStatus(ctx, currentInfra, metav1.UpdateOptions{})
+	platformStatus, err := json.Marshal(currentInfra.Status.PlatformStatus)
 	if err != nil {
-		syncCtx.Recorder().Warningf("MigrationPlatformStatusController", "Unable to update the infrastructure status")
 		return err
 	}
-	return nil
+	owned, err := changedPlatformStatus(obji.Status.PlatformStatus, currentInfra.Status.PlatformStatus)
+	if err != nil {
+		return err
+	}
+	_, err = c.migrator.Sync(ctx, syncCtx.Recorder(), &migration.Change{
+		Description: fmt.Sprintf("infrastructures.%s/cluster status.platformStatus -> %s", configv1.GroupName, platformStatus),
+		// the status subresource of the Infrastructure drops metadata, so the audit annotation cannot be part of the
+		// status apply. The migration is recorded in the MigrationPending condition and the event instead.
+		Apply: func(ctx context.Context, _ map[string]string) error {
+			return infrastructurestatus.Apply(ctx, c.infraClient, syncCtx.Recorder(), fieldManager,
+				applyconfigv1.InfrastructureStatus().WithPlatformStatus(owned))
+		},
+	})
+	return err
 }
 
-func (c MigrationPlatformStatusController) migratePlatformSpecificFields(ctx context.Context, currentInfra *configv1.Infrastructure) error {
-	if currentInfra.Status.PlatformStatus.Type != configv1.AWSPlatformType {
-		// only AWS has platform-specific fields to migrate
+// changedPlatformStatus returns an apply configuration holding only the fields of desired that differ from existing,
+// so that the controller only takes ownership of the fields it backfilled.
+func changedPlatformStatus(existing, desired *configv1.PlatformStatus) (*applyconfigv1.PlatformStatusApplyConfiguration, error) {
+	existingFields, err := toFields(existing)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := toFields(desired)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := json.Marshal(changedFields(existingFields, desiredFields))
+	if err != nil {
+		return nil, err
+	}
+	owned := applyconfigv1.PlatformStatus()
+	if err := json.Unmarshal(changed, owned); err != nil {
+		return nil, err
+	}
+	return owned, nil
+}
+
+func toFields(status *configv1.PlatformStatus) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if status == nil {
+		return fields, nil
+	}
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	return fields, json.Unmarshal(raw, &fields)
+}
+
+// changedFields returns the fields of desired that are missing from or differ from existing, descending into
+// objects. Lists are compared as a whole.
+func changedFields(existing, desired map[string]interface{}) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for key, value := range desired {
+		existingValue, ok := existing[key]
+		if ok && equality.Semantic.DeepEqual(existingValue, value) {
+			continue
+		}
+		existingObj, existingIsObj := existingValue.(map[string]interface{})
+		obj, isObj := value.(map[string]interface{})
+		if existingIsObj && isObj {
+			changed[key] = changedFields(existingObj, obj)
+			continue
+		}
+		changed[key] = value
+	}
+	return changed
+}
+
+func (c *MigrationPlatformStatusController) migratePlatformSpecificFields(ctx context.Context, recorder events.Recorder, currentInfra *configv1.Infrastructure) error {
+	if currentInfra.Status.PlatformStatus.Type == configv1.AWSPlatformType {
+		return c.migrateAWSFields(ctx, currentInfra)
+	}
+
+	backfill, ok := platformBackfills[currentInfra.Status.PlatformStatus.Type]
+	if !ok || !backfill.missing(currentInfra.Status.PlatformStatus) {
+		c.reportedMissing = ""
+		return nil
+	}
+
+	cc, err := loadClusterConfig(ctx, c.configMapClient)
+	if err != nil {
+		// unlike the AWS region, these fields are optional for consumers, so a cluster without
+		// a usable install-config is left as is instead of degrading the operator.
+		klog.Warningf("Unable to backfill %s platform status fields: %v", currentInfra.Status.PlatformStatus.Type, err)
 		return nil
 	}
+	missing := strings.Join(backfill.fill(currentInfra, &cc), ", ")
+	if len(missing) > 0 && missing != c.reportedMissing {
+		// the values are not guessed, the fields stay empty until the install-config has them
+		recorder.Warningf("MigrationPlatformStatusController", "Unable to backfill %s platform status fields, %s not set in %s/%s",
+			currentInfra.Status.PlatformStatus.Type, missing, clusterConfigNamespace, clusterConfigName)
+	}
+	c.reportedMissing = missing
 
+	return nil
+}
+
+func (c *MigrationPlatformStatusController) migrateAWSFields(ctx context.Context, currentInfra *configv1.Infrastructure) error {
 	if currentInfra.Status.PlatformStatus.AWS == nil {
 		currentInfra.Status.PlatformStatus.AWS = &configv1.AWSPlatformStatus{}
 	}
@@ -134,6 +240,138 @@ func (c MigrationPlatformStatusController) migratePlatformSpecificFields(ctx con
 	return nil
 }
 
+// platformBackfill fills the platform status fields that older clusters may be missing from the install-config.
+// Only empty fields are filled, and platform status structs are only created when there is something to fill.
+type platformBackfill struct {
+	// missing returns true if any of the fields handled by fill are unset.
+	missing func(status *configv1.PlatformStatus) bool
+	// fill returns the install-config fields that are required to fill an empty field but are not set.
+	fill func(infra *configv1.Infrastructure, cc *installConfig) []string
+}
+
+var platformBackfills = map[configv1.PlatformType]platformBackfill{
+	configv1.GCPPlatformType: {
+		missing: func(status *configv1.PlatformStatus) bool {
+			return status.GCP == nil || status.GCP.ProjectID == "" || status.GCP.Region == ""
+		},
+		fill: func(infra *configv1.Infrastructure, cc *installConfig) []string {
+			in := cc.Platform.GCP
+			if in == nil || (in.ProjectID == "" && in.Region == "") {
+				return nil
+			}
+			status := infra.Status.PlatformStatus
+			if status.GCP == nil {
+				status.GCP = &configv1.GCPPlatformStatus{}
+			}
+			setIfEmpty(&status.GCP.ProjectID, in.ProjectID)
+			setIfEmpty(&status.GCP.Region, in.Region)
+			return nil
+		},
+	},
+	configv1.AzurePlatformType: {
+		missing: func(status *configv1.PlatformStatus) bool {
+			return status.Azure == nil || status.Azure.CloudName == "" || status.Azure.ResourceGroupName == ""
+		},
+		fill: func(infra *configv1.Infrastructure, cc *installConfig) []string {
+			in := cc.Platform.Azure
+			if in == nil {
+				return nil
+			}
+			status := infra.Status.PlatformStatus
+			var missing []string
+			if in.ResourceGroupName == "" && (status.Azure == nil || status.Azure.ResourceGroupName == "") {
+				missing = append(missing, "platform.azure.resourceGroupName")
+			}
+			if in.CloudName == "" && in.ResourceGroupName == "" {
+				return missing
+			}
+			if status.Azure == nil {
+				status.Azure = &configv1.AzurePlatformStatus{}
+			}
+			if status.Azure.CloudName == "" {
+				status.Azure.CloudName = configv1.AzureCloudEnvironment(in.CloudName)
+			}
+			setIfEmpty(&status.Azure.ResourceGroupName, in.ResourceGroupName)
+			return missing
+		},
+	},
+	configv1.IBMCloudPlatformType: {
+		missing: func(status *configv1.PlatformStatus) bool {
+			return status.IBMCloud == nil || status.IBMCloud.Location == ""
+		},
+		fill: func(infra *configv1.Infrastructure, cc *installConfig) []string {
+			in := cc.Platform.IBMCloud
+			if in == nil || in.Region == "" {
+				return nil
+			}
+			status := infra.Status.PlatformStatus
+			if status.IBMCloud == nil {
+				status.IBMCloud = &configv1.IBMCloudPlatformStatus{}
+			}
+			status.IBMCloud.Location = in.Region
+			return nil
+		},
+	},
+	configv1.PowerVSPlatformType: {
+		missing: func(status *configv1.PlatformStatus) bool {
+			return status.PowerVS == nil || status.PowerVS.Region == "" || status.PowerVS.Zone == ""
+		},
+		fill: func(infra *configv1.Infrastructure, cc *installConfig) []string {
+			in := cc.Platform.PowerVS
+			if in == nil || (in.Region == "" && in.Zone == "") {
+				return nil
+			}
+			status := infra.Status.PlatformStatus
+			if status.PowerVS == nil {
+				status.PowerVS = &configv1.PowerVSPlatformStatus{}
+			}
+			setIfEmpty(&status.PowerVS.Region, in.Region)
+			setIfEmpty(&status.PowerVS.Zone, in.Zone)
+			return nil
+		},
+	},
+	configv1.VSpherePlatformType: {
+		missing: func(status *configv1.PlatformStatus) bool {
+			return status.VSphere == nil || len(status.VSphere.APIServerInternalIPs) == 0 || len(status.VSphere.IngressIPs) == 0
+		},
+		fill: func(infra *configv1.Infrastructure, cc *installConfig) []string {
+			in := cc.Platform.VSphere
+			if in == nil || !in.hasVIPs() {
+				return nil
+			}
+			status := infra.Status.PlatformStatus
+			if status.VSphere == nil {
+				status.VSphere = &configv1.VSpherePlatformStatus{}
+			}
+			in.fillVIPs(&status.VSphere.APIServerInternalIPs, &status.VSphere.APIServerInternalIP, &status.VSphere.IngressIPs, &status.VSphere.IngressIP)
+			return nil
+		},
+	},
+	configv1.OpenStackPlatformType: {
+		missing: func(status *configv1.PlatformStatus) bool {
+			return status.OpenStack == nil || len(status.OpenStack.APIServerInternalIPs) == 0 || len(status.OpenStack.IngressIPs) == 0
+		},
+		fill: func(infra *configv1.Infrastructure, cc *installConfig) []string {
+			in := cc.Platform.OpenStack
+			if in == nil || !in.hasVIPs() {
+				return nil
+			}
+			status := infra.Status.PlatformStatus
+			if status.OpenStack == nil {
+				status.OpenStack = &configv1.OpenStackPlatformStatus{}
+			}
+			in.fillVIPs(&status.OpenStack.APIServerInternalIPs, &status.OpenStack.APIServerInternalIP, &status.OpenStack.IngressIPs, &status.OpenStack.IngressIP)
+			return nil
+		},
+	},
+}
+
+func setIfEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
 func loadClusterConfig(ctx context.Context, client corev1client.ConfigMapsGetter) (installConfig, error) {
 	obj, err := client.ConfigMaps(clusterConfigNamespace).Get(ctx, clusterConfigName, metav1.GetOptions{})
 	if err != nil {
@@ -156,5 +394,70 @@ type installConfig struct {
 		AWS *struct {
 			Region string `json:"region"`
 		} `json:"aws,omitempty"`
+		GCP *struct {
+			ProjectID string `json:"projectID"`
+			Region    string `json:"region"`
+		} `json:"gcp,omitempty"`
+		Azure *struct {
+			CloudName         string `json:"cloudName"`
+			ResourceGroupName string `json:"resourceGroupName"`
+		} `json:"azure,omitempty"`
+		IBMCloud *struct {
+			Region string `json:"region"`
+		} `json:"ibmcloud,omitempty"`
+		PowerVS *struct {
+			Region string `json:"region"`
+			Zone   string `json:"zone"`
+		} `json:"powervs,omitempty"`
+		VSphere   *vipConfig `json:"vsphere,omitempty"`
+		OpenStack *vipConfig `json:"openstack,omitempty"`
 	} `json:"platform"`
 }
+
+// vipConfig holds the API and ingress VIPs of the on-prem platforms, including the deprecated single VIP fields.
+type vipConfig struct {
+	APIVIPs     []string `json:"apiVIPs"`
+	APIVIP      string   `json:"apiVIP"`
+	IngressVIPs []string `json:"ingressVIPs"`
+	IngressVIP  string   `json:"ingressVIP"`
+}
+
+func (v *vipConfig) hasVIPs() bool {
+	return len(v.apiVIPs()) > 0 || len(v.ingressVIPs()) > 0
+}
+
+func (v *vipConfig) apiVIPs() []string {
+	if len(v.APIVIPs) > 0 {
+		return v.APIVIPs
+	}
+	if v.APIVIP != "" {
+		return []string{v.APIVIP}
+	}
+	return nil
+}
+
+func (v *vipConfig) ingressVIPs() []string {
+	if len(v.IngressVIPs) > 0 {
+		return v.IngressVIPs
+	}
+	if v.IngressVIP != "" {
+		return []string{v.IngressVIP}
+	}
+	return nil
+}
+
+// fillVIPs sets the unset status VIP fields, keeping the deprecated single IP fields in sync with the first VIP.
+func (v *vipConfig) fillVIPs(apiIPs *[]string, apiIP *string, ingressIPs *[]string, ingressIP *string) {
+	if len(*apiIPs) == 0 {
+		if vips := v.apiVIPs(); len(vips) > 0 {
+			*apiIPs = append([]string{}, vips...)
+			setIfEmpty(apiIP, vips[0])
+		}
+	}
+	if len(*ingressIPs) == 0 {
+		if vips := v.ingressVIPs(); len(vips) > 0 {
+			*ingressIPs = append([]string{}, vips...)
+			setIfEmpty(ingressIP, vips[0])
+		}
+	}
+}
@@ -2,17 +2,23 @@ package migration_platform_status
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	configfakeclient "github.com/openshift/client-go/config/clientset/versioned/fake"
 	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/migration"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
+	operatorv1helpers "github.com/openshift/library-go/pkg/operator/v1helpers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
@@ -27,17 +33,16 @@ func Test_sync(t *testing.T) {
 		outputstatus configv1.InfrastructureStatus
 		err          string
 		actions      int
+		warning      string
 	}{{
-		inputstatus:  configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType},
-		outputstatus: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType}},
-		actions:      1,
+		// waits for the InfrastructureNormalizerController to set status.platformStatus.type
+		inputstatus:  configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		actions:      0,
 	}, {
-		inputstatus:  configv1.InfrastructureStatus{Platform: configv1.PlatformType("random")},
+		inputstatus:  configv1.InfrastructureStatus{Platform: configv1.PlatformType("random"), PlatformStatus: &configv1.PlatformStatus{Type: "random"}},
 		outputstatus: configv1.InfrastructureStatus{Platform: configv1.PlatformType("random"), PlatformStatus: &configv1.PlatformStatus{Type: "random"}},
-		actions:      1,
-	}, {
-		inputstatus: configv1.InfrastructureStatus{Platform: configv1.PlatformType("oldType"), PlatformStatus: &configv1.PlatformStatus{Type: "newType"}},
-		err:         `^Mis-match between status\.platform \(oldType\) and status\.platformStatus\.type \(newType\) in infrastructures\.config\.openshift\.io/cluster$`,
+		actions:      0,
 	}, {
 		inputstatus:  configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType}},
 		outputstatus: configv1.InfrastructureStatus{Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType}},
@@ -58,14 +63,11 @@ func Test_sync(t *testing.T) {
 		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
 		err:         `^install-config key doesn't exist in ConfigMap kube-system/cluster-config-v1$`,
 	}, {
-		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
-		err:         `^install-config key doesn't exist in ConfigMap kube-system/cluster-config-v1$`,
-	}, {
-		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
 		inputdata:   map[string]string{"random-key": "random-value"},
 		err:         `^install-config key doesn't exist in ConfigMap kube-system/cluster-config-v1$`,
 	}, {
-		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
 		inputdata: map[string]string{
 			"install-config": `apiVersion: v1
 baseDomain: testing.openshift.com
@@ -96,7 +98,7 @@ sshKey: REDACTED`,
 		},
 		err: `^no AWS configuration found in cluster-config-v1$`,
 	}, {
-		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
 		inputdata: map[string]string{
 			"install-config": `{
   "apiVersion": "v1",
@@ -141,7 +143,7 @@ sshKey: REDACTED`,
 		},
 		err: `^no AWS configuration found in cluster-config-v1$`,
 	}, {
-		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType},
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}},
 		inputdata: map[string]string{
 			"install-config": `apiVersion: v1
 baseDomain: testing.openshift.com
@@ -171,6 +173,112 @@ sshKey: REDACTED`,
 		},
 		outputstatus: configv1.InfrastructureStatus{Platform: configv1.AWSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "testing-region"}}},
 		actions:      1,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  gcp:
+    projectID: test-project
+    region: test-region`,
+		},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType, GCP: &configv1.GCPPlatformStatus{ProjectID: "test-project", Region: "test-region"}}},
+		actions:      1,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType, GCP: &configv1.GCPPlatformStatus{ProjectID: "existing-project"}}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  gcp:
+    projectID: test-project
+    region: test-region`,
+		},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.GCPPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.GCPPlatformType, GCP: &configv1.GCPPlatformStatus{ProjectID: "existing-project", Region: "test-region"}}},
+		actions:      2,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{InfrastructureName: "testing-abcde", Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  azure:
+    baseDomainResourceGroupName: os4-common
+    cloudName: AzureUSGovernmentCloud
+    region: centralus`,
+		},
+		// the resource group is not guessed
+		outputstatus: configv1.InfrastructureStatus{InfrastructureName: "testing-abcde", Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzureUSGovernmentCloud}}},
+		actions:      1,
+		warning:      "Unable to backfill Azure platform status fields, platform.azure.resourceGroupName not set in kube-system/cluster-config-v1",
+	}, {
+		inputstatus: configv1.InfrastructureStatus{InfrastructureName: "testing-abcde", Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzurePublicCloud}}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  azure:
+    cloudName: AzureUSGovernmentCloud
+    resourceGroupName: user-rg`,
+		},
+		outputstatus: configv1.InfrastructureStatus{InfrastructureName: "testing-abcde", Platform: configv1.AzurePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzurePublicCloud, ResourceGroupName: "user-rg"}}},
+		actions:      2,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.IBMCloudPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.IBMCloudPlatformType}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  ibmcloud:
+    region: us-south`,
+		},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.IBMCloudPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.IBMCloudPlatformType, IBMCloud: &configv1.IBMCloudPlatformStatus{Location: "us-south"}}},
+		actions:      1,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.PowerVSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.PowerVSPlatformType}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  powervs:
+    region: dal
+    zone: dal10`,
+		},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.PowerVSPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.PowerVSPlatformType, PowerVS: &configv1.PowerVSPlatformStatus{Region: "dal", Zone: "dal10"}}},
+		actions:      1,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.VSpherePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.VSpherePlatformType}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  vsphere:
+    apiVIP: 192.168.1.10
+    ingressVIP: 192.168.1.11`,
+		},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.VSpherePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.VSpherePlatformType, VSphere: &configv1.VSpherePlatformStatus{
+			APIServerInternalIP: "192.168.1.10", APIServerInternalIPs: []string{"192.168.1.10"},
+			IngressIP: "192.168.1.11", IngressIPs: []string{"192.168.1.11"},
+		}}},
+		actions: 1,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.OpenStackPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.OpenStackPlatformType, OpenStack: &configv1.OpenStackPlatformStatus{CloudName: "openstack"}}},
+		inputdata: map[string]string{
+			"install-config": `apiVersion: v1
+platform:
+  openstack:
+    apiVIPs: [10.0.0.5, "fd2e:6f44:5dd8::5"]
+    ingressVIPs: [10.0.0.7, "fd2e:6f44:5dd8::7"]`,
+		},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.OpenStackPlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.OpenStackPlatformType, OpenStack: &configv1.OpenStackPlatformStatus{
+			CloudName:           "openstack",
+			APIServerInternalIP: "10.0.0.5", APIServerInternalIPs: []string{"10.0.0.5", "fd2e:6f44:5dd8::5"},
+			IngressIP: "10.0.0.7", IngressIPs: []string{"10.0.0.7", "fd2e:6f44:5dd8::7"},
+		}}},
+		// the existing openstack status holds null VIP lists owned by "before-first-apply", so the first apply
+		// conflicts and the fields are taken over by a forced apply
+		actions: 2,
+	}, {
+		inputstatus: configv1.InfrastructureStatus{Platform: configv1.VSpherePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.VSpherePlatformType}},
+		inputdata: map[string]string{"install-config": `apiVersion: v1
+platform:
+  vsphere: {}`},
+		outputstatus: configv1.InfrastructureStatus{Platform: configv1.VSpherePlatformType, PlatformStatus: &configv1.PlatformStatus{Type: configv1.VSpherePlatformType}},
+		actions:      0,
 	}}
 	for _, test := range cases {
 		t.Run("", func(t *testing.T) {
@@ -179,7 +287,7 @@ sshKey: REDACTED`,
 			if err := indexerInfra.Add(infra); err != nil {
 				t.Fatal(err.Error())
 			}
-			fakeConfig := configfakeclient.NewSimpleClientset(infra)
+			fakeConfig := configfakeclient.NewClientset(infra)
 
 			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "kube-system"}, Data: test.inputdata}
 			fake := fake.NewSimpleClientset(cm)
@@ -188,18 +296,37 @@ sshKey: REDACTED`,
 				infraClient:     fakeConfig.ConfigV1().Infrastructures(),
 				infraLister:     configv1listers.NewInfrastructureLister(indexerInfra),
 				configMapClient: fake.CoreV1(),
+				migrator: migration.NewMigrator("MigrationPlatformStatusController",
+					operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+					clocktesting.NewFakePassiveClock(time.Now())),
 			}
 
-			err := ctrl.sync(context.TODO(),
-				factory.NewSyncContext("MigrationPlatformStatusController", events.NewInMemoryRecorder("MigrationPlatformStatusController", clocktesting.NewFakePassiveClock(time.Now()))))
+			recorder := events.NewInMemoryRecorder("MigrationPlatformStatusController", clocktesting.NewFakePassiveClock(time.Now()))
+			err := ctrl.sync(context.TODO(), factory.NewSyncContext("MigrationPlatformStatusController", recorder))
+			var warnings []string
+			for _, event := range recorder.Events() {
+				if event.Type == corev1.EventTypeWarning {
+					warnings = append(warnings, event.Message)
+				}
+			}
+			if test.warning != "" {
+				assert.Contains(t, warnings, test.warning)
+			}
 			if test.err == "" {
 				assert.NoError(t, err)
-				assert.Equal(t, test.actions, len(fakeConfig.Actions()))
-				got := infra.DeepCopy()
+				// The seeded object has no managed fields, so its zero values are owned by "before-first-apply".
+				// Backfilling them conflicts on the first apply and takes two status writes.
+				updates := 0
 				for _, a := range fakeConfig.Actions() {
-					obj := a.(ktesting.UpdateAction).GetObject().(*configv1.Infrastructure)
-					got.Status = obj.Status
+					if a, ok := a.(ktesting.PatchAction); ok {
+						assert.Equal(t, types.ApplyPatchType, a.GetPatchType())
+						assert.Equal(t, "status", a.GetSubresource())
+						updates++
+					}
 				}
+				assert.Equal(t, test.actions, updates)
+				got, err := fakeConfig.ConfigV1().Infrastructures().Get(context.TODO(), "cluster", metav1.GetOptions{})
+				require.NoError(t, err)
 				assert.EqualValues(t, test.outputstatus, got.Status)
 			} else if assert.Error(t, err) {
 				assert.Regexp(t, test.err, err.Error())
@@ -207,3 +334,53 @@ sshKey: REDACTED`,
 		})
 	}
 }
+
+func Test_sync_reportsMissingFieldsOnce(t *testing.T) {
+	infra := &configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Status: configv1.InfrastructureStatus{
+		Platform:       configv1.AzurePlatformType,
+		PlatformStatus: &configv1.PlatformStatus{Type: configv1.AzurePlatformType, Azure: &configv1.AzurePlatformStatus{CloudName: configv1.AzurePublicCloud}},
+	}}
+	indexerInfra := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexerInfra.Add(infra))
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cluster-config-v1", Namespace: "kube-system"}, Data: map[string]string{"install-config": `apiVersion: v1
+platform:
+  azure:
+    cloudName: AzurePublicCloud`}}
+	fake := fake.NewSimpleClientset(cm)
+
+	ctrl := &MigrationPlatformStatusController{
+		infraClient:     configfakeclient.NewClientset(infra).ConfigV1().Infrastructures(),
+		infraLister:     configv1listers.NewInfrastructureLister(indexerInfra),
+		configMapClient: fake.CoreV1(),
+		migrator: migration.NewMigrator("MigrationPlatformStatusController",
+			operatorv1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil),
+			clocktesting.NewFakePassiveClock(time.Now())),
+	}
+	warnings := func() []string {
+		recorder := events.NewInMemoryRecorder("MigrationPlatformStatusController", clocktesting.NewFakePassiveClock(time.Now()))
+		require.NoError(t, ctrl.sync(context.TODO(), factory.NewSyncContext("MigrationPlatformStatusController", recorder)))
+		var warnings []string
+		for _, event := range recorder.Events() {
+			if event.Type == corev1.EventTypeWarning {
+				warnings = append(warnings, event.Message)
+			}
+		}
+		return warnings
+	}
+
+	const warning = "Unable to backfill Azure platform status fields, platform.azure.resourceGroupName not set in kube-system/cluster-config-v1"
+	assert.Equal(t, []string{warning}, warnings())
+	// the resyncs do not report the same missing fields again
+	assert.Empty(t, warnings())
+	assert.Empty(t, warnings())
+
+	// the fields are reported again once they were found and went missing again
+	cm.Data["install-config"] += "\n    resourceGroupName: user-rg"
+	_, err := fake.CoreV1().ConfigMaps("kube-system").Update(context.TODO(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, warnings())
+	cm.Data["install-config"] = strings.TrimSuffix(cm.Data["install-config"], "\n    resourceGroupName: user-rg")
+	_, err = fake.CoreV1().ConfigMaps("kube-system").Update(context.TODO(), cm, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{warning}, warnings())
+}
@@ -0,0 +1,55 @@
+package operatorclient
+
+import (
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// UnsupportedConfigOverrides holds the settings this operator reads from the operator Config spec.unsupportedConfigOverrides.
+type UnsupportedConfigOverrides struct {
+	Migration       MigrationOverrides       `json:"migration,omitempty"`
+	KubeCloudConfig KubeCloudConfigOverrides `json:"kubeCloudConfig,omitempty"`
+	// DisabledControllers lists the names of the controllers that must not sync, e.g. AWSPlatformServiceLocationController.
+	DisabledControllers []string `json:"disabledControllers,omitempty"`
+}
+
+// IsControllerDisabled returns true if the named controller is listed in DisabledControllers.
+func (o *UnsupportedConfigOverrides) IsControllerDisabled(controllerName string) bool {
+	for _, name := range o.DisabledControllers {
+		if name == controllerName {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrationOverrides tunes the behaviour of the migration controllers.
+type MigrationOverrides struct {
+	// DryRun makes the migration controllers report the changes they would make instead of writing them.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// KubeCloudConfigOverrides selects the kinds of the objects the KubeCloudConfigController reads and writes.
+type KubeCloudConfigOverrides struct {
+	// SourceKind is the kind of the user-provided openshift-config/<infrastructure.spec.cloudConfig.name>,
+	// ConfigMap or Secret. Defaults to ConfigMap.
+	SourceKind string `json:"sourceKind,omitempty"`
+	// TargetKind is the kind of the openshift-config-managed/kube-cloud-config written by the controller,
+	// ConfigMap or Secret. Defaults to ConfigMap, and must be Secret when SourceKind is. Consumers read the
+	// kube-cloud-config of this kind, the one of the other kind is deleted.
+	TargetKind string `json:"targetKind,omitempty"`
+}
+
+// GetUnsupportedConfigOverrides parses the unsupportedConfigOverrides of the operator spec.
+func GetUnsupportedConfigOverrides(spec *operatorv1.OperatorSpec) (*UnsupportedConfigOverrides, error) {
+	ret := &UnsupportedConfigOverrides{}
+	if spec == nil || len(spec.UnsupportedConfigOverrides.Raw) == 0 {
+		return ret, nil
+	}
+	if err := yaml.Unmarshal(spec.UnsupportedConfigOverrides.Raw, ret); err != nil {
+		return nil, fmt.Errorf("unable to parse spec.unsupportedConfigOverrides: %w", err)
+	}
+	return ret, nil
+}
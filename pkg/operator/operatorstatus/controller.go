@@ -0,0 +1,277 @@
+package operatorstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	kubecloudconfig "github.com/openshift/cluster-config-operator/pkg/operator/kube_cloud_config"
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// ConfigOperatorController computes the OperatorAvailable and OperatorProgressing conditions from the objects the
+// operator reads and writes, or from spec.managementState when the operator is not Managed.
+// The operator is not available while the Infrastructure, FeatureGate or ClusterVersion it requires are missing,
+// and it is progressing while the FeatureGate status has no entry for the operator version or while
+// openshift-config-managed/kube-cloud-config does not match its desired content. Progressing is Unknown when the
+// kube-cloud-config cannot be compared with its desired content, e.g. before the feature gates are observed.
+type ConfigOperatorController struct {
+	operatorClient  v1helpers.OperatorClient
+	operatorVersion string
+
+	infraLister            configv1listers.InfrastructureLister
+	featureGateLister      configv1listers.FeatureGateLister
+	clusterVersionLister   configv1listers.ClusterVersionLister
+	userConfigMapLister    corev1listers.ConfigMapNamespaceLister
+	managedConfigMapLister corev1listers.ConfigMapNamespaceLister
+	featureGateAccessor    featuregates.FeatureGateAccess
+}
+
+// NewController returns a ConfigOperatorController
+func NewController(operatorClient v1helpers.OperatorClient, operatorVersion string,
+	infraInformer configv1informers.InfrastructureInformer,
+	featureGateInformer configv1informers.FeatureGateInformer,
+	clusterVersionInformer configv1informers.ClusterVersionInformer,
+	userConfigMapInformer corev1informers.ConfigMapInformer,
+	managedConfigMapInformer corev1informers.ConfigMapInformer,
+	featureGateAccessor featuregates.FeatureGateAccess,
+	recorder events.Recorder) factory.Controller {
+	c := &ConfigOperatorController{
+		operatorClient:         operatorClient,
+		operatorVersion:        operatorVersion,
+		infraLister:            infraInformer.Lister(),
+		featureGateLister:      featureGateInformer.Lister(),
+		clusterVersionLister:   clusterVersionInformer.Lister(),
+		userConfigMapLister:    userConfigMapInformer.Lister().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace),
+		managedConfigMapLister: managedConfigMapInformer.Lister().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace),
+		featureGateAccessor:    featureGateAccessor,
+	}
+	return factory.New().
+		WithInformers(
+			operatorClient.Informer(),
+			infraInformer.Informer(),
+			featureGateInformer.Informer(),
+			clusterVersionInformer.Informer(),
+			userConfigMapInformer.Informer(),
+			managedConfigMapInformer.Informer(),
+		).
+		WithSync(c.sync).
+		ResyncEvery(time.Minute).
+		ToController("ConfigOperatorController", recorder)
+}
+
+func (c *ConfigOperatorController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	spec, _, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return err
+	}
+
+	managementStateDegraded := operatorv1.OperatorCondition{
+		Type:   "ManagementStateDegraded",
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	var available, progressing operatorv1.OperatorCondition
+	switch spec.ManagementState {
+	case operatorv1.Managed, "":
+		available, err = c.availableCondition()
+		if err != nil {
+			return err
+		}
+		progressing, err = c.progressingCondition(spec)
+		if err != nil {
+			return err
+		}
+	case operatorv1.Unmanaged:
+		available = operatorv1.OperatorCondition{Type: "OperatorAvailable", Status: operatorv1.ConditionUnknown, Reason: "Unmanaged",
+			Message: "The operator is Unmanaged, its controllers don't update the cluster"}
+		progressing = operatorv1.OperatorCondition{Type: "OperatorProgressing", Status: operatorv1.ConditionUnknown, Reason: "Unmanaged",
+			Message: "The operator is Unmanaged, its controllers don't update the cluster"}
+	case operatorv1.Removed:
+		available = operatorv1.OperatorCondition{Type: "OperatorAvailable", Status: operatorv1.ConditionTrue, Reason: "Removed",
+			Message: "The operator is Removed, the content it manages is deleted"}
+		progressing = operatorv1.OperatorCondition{Type: "OperatorProgressing", Status: operatorv1.ConditionFalse, Reason: "Removed"}
+	default:
+		message := fmt.Sprintf("Unsupported managementState %q, the operator's controllers don't update the cluster", spec.ManagementState)
+		available = operatorv1.OperatorCondition{Type: "OperatorAvailable", Status: operatorv1.ConditionUnknown, Reason: "UnsupportedManagementState", Message: message}
+		progressing = operatorv1.OperatorCondition{Type: "OperatorProgressing", Status: operatorv1.ConditionUnknown, Reason: "UnsupportedManagementState", Message: message}
+		managementStateDegraded = operatorv1.OperatorCondition{Type: "ManagementStateDegraded", Status: operatorv1.ConditionTrue, Reason: "Unsupported", Message: message}
+	}
+
+	operatorStatus, updated, updateErr := v1helpers.UpdateStatus(ctx, c.operatorClient,
+		v1helpers.UpdateConditionFn(available),
+		v1helpers.UpdateConditionFn(progressing),
+		v1helpers.UpdateConditionFn(managementStateDegraded),
+	)
+	if updated && operatorStatus != nil {
+		syncCtx.Recorder().Eventf("ConfigOperatorStatusChanged", "Operator conditions updated: %s", spew.Sprint(operatorStatus.Conditions))
+	}
+	return updateErr
+}
+
+func (c *ConfigOperatorController) availableCondition() (operatorv1.OperatorCondition, error) {
+	var missing []string
+	if _, err := c.infraLister.Get("cluster"); apierrors.IsNotFound(err) {
+		missing = append(missing, fmt.Sprintf("infrastructures.%s/cluster", configv1.GroupName))
+	} else if err != nil {
+		return operatorv1.OperatorCondition{}, err
+	}
+	if _, err := c.featureGateLister.Get("cluster"); apierrors.IsNotFound(err) {
+		missing = append(missing, fmt.Sprintf("featuregates.%s/cluster", configv1.GroupName))
+	} else if err != nil {
+		return operatorv1.OperatorCondition{}, err
+	}
+	if _, err := c.clusterVersionLister.Get("version"); apierrors.IsNotFound(err) {
+		missing = append(missing, fmt.Sprintf("clusterversions.%s/version", configv1.GroupName))
+	} else if err != nil {
+		return operatorv1.OperatorCondition{}, err
+	}
+
+	if len(missing) > 0 {
+		return operatorv1.OperatorCondition{
+			Type:    "OperatorAvailable",
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "RequiredObjectsMissing",
+			Message: fmt.Sprintf("Required objects not found: %s", strings.Join(missing, ", ")),
+		}, nil
+	}
+	return operatorv1.OperatorCondition{
+		Type:   "OperatorAvailable",
+		Status: operatorv1.ConditionTrue,
+		Reason: "AsExpected",
+	}, nil
+}
+
+func (c *ConfigOperatorController) progressingCondition(spec *operatorv1.OperatorSpec) (operatorv1.OperatorCondition, error) {
+	var reasons, messages []string
+
+	featureGates, err := c.featureGateLister.Get("cluster")
+	switch {
+	case apierrors.IsNotFound(err):
+		// reported by OperatorAvailable
+	case err != nil:
+		return operatorv1.OperatorCondition{}, err
+	case !hasFeatureGateVersion(featureGates, c.operatorVersion):
+		reasons = append(reasons, "FeatureGateStatusUpdating")
+		messages = append(messages, fmt.Sprintf("featuregates.%s/cluster status has no feature gates for version %s", configv1.GroupName, c.operatorVersion))
+	}
+
+	upToDate, unknown, err := c.kubeCloudConfigUpToDate(spec)
+	if err != nil {
+		return operatorv1.OperatorCondition{}, err
+	}
+	if !upToDate {
+		reasons = append(reasons, "KubeCloudConfigUpdating")
+		messages = append(messages, fmt.Sprintf("%s/%s does not match its desired content", operatorclient.GlobalMachineSpecifiedConfigNamespace, kubecloudconfig.TargetConfigName))
+	}
+
+	if len(reasons) > 0 {
+		return operatorv1.OperatorCondition{
+			Type:    "OperatorProgressing",
+			Status:  operatorv1.ConditionTrue,
+			Reason:  strings.Join(reasons, "And"),
+			Message: strings.Join(messages, "\n"),
+		}, nil
+	}
+	if len(unknown) > 0 {
+		return operatorv1.OperatorCondition{
+			Type:    "OperatorProgressing",
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "KubeCloudConfigUnknown",
+			Message: unknown,
+		}, nil
+	}
+	return operatorv1.OperatorCondition{
+		Type:   "OperatorProgressing",
+		Status: operatorv1.ConditionFalse,
+		Reason: "AsExpected",
+	}, nil
+}
+
+func hasFeatureGateVersion(featureGates *configv1.FeatureGate, version string) bool {
+	for _, details := range featureGates.Status.FeatureGates {
+		if details.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// kubeCloudConfigUpToDate compares the kube-cloud-config with the content the KubeCloudConfigController converges to.
+// When they cannot be compared, it returns a message explaining why.
+func (c *ConfigOperatorController) kubeCloudConfigUpToDate(spec *operatorv1.OperatorSpec) (bool, string, error) {
+	target := fmt.Sprintf("%s/%s", operatorclient.GlobalMachineSpecifiedConfigNamespace, kubecloudconfig.TargetConfigName)
+	overrides, err := operatorclient.GetUnsupportedConfigOverrides(spec)
+	if err != nil {
+		return true, fmt.Sprintf("Unable to compare %s with its desired content: %v", target, err), nil
+	}
+	if kinds := overrides.KubeCloudConfig; kinds.SourceKind == "Secret" || kinds.TargetKind == "Secret" {
+		return true, fmt.Sprintf("The content of %s is not compared in a Secret-backed configuration", target), nil
+	}
+	if c.featureGateAccessor != nil && !c.featureGateAccessor.AreInitialFeatureGatesObserved() {
+		return true, fmt.Sprintf("Unable to compare %s with its desired content before the feature gates are observed", target), nil
+	}
+
+	infra, err := c.infraLister.Get("cluster")
+	if apierrors.IsNotFound(err) {
+		// reported by OperatorAvailable
+		return true, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	source := &corev1.ConfigMap{}
+	if name := infra.Spec.CloudConfig.Name; len(name) > 0 {
+		obj, err := c.userConfigMapLister.Get(name)
+		if apierrors.IsNotFound(err) {
+			return true, fmt.Sprintf("Unable to compare %s with its desired content: source %s/%s not found", target, operatorclient.GlobalUserSpecifiedConfigNamespace, name), nil
+		}
+		if err != nil {
+			return false, "", err
+		}
+		obj.DeepCopyInto(source)
+	}
+
+	desired, managed, err := kubecloudconfig.DesiredConfigMap(infra, source, c.featureGateAccessor)
+	if err != nil {
+		return true, fmt.Sprintf("Unable to compare %s with its desired content: %v", target, err), nil
+	}
+	if !managed {
+		return true, "", nil
+	}
+
+	current, err := c.managedConfigMapLister.Get(kubecloudconfig.TargetConfigName)
+	if apierrors.IsNotFound(err) {
+		return desired == nil, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if desired == nil {
+		return false, "", nil
+	}
+	return equalData(current.Data, desired.Data) && equalData(current.BinaryData, desired.BinaryData), "", nil
+}
+
+func equalData[T any](a, b map[string]T) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return equality.Semantic.DeepEqual(a, b)
+}
@@ -0,0 +1,211 @@
+package operatorstatus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func Test_sync(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"},
+		},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{Type: configv1.NonePlatformType},
+		},
+	}
+	featureGate := &configv1.FeatureGate{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.FeatureGateStatus{
+			FeatureGates: []configv1.FeatureGateDetails{{Version: "4.20.0"}},
+		},
+	}
+	clusterVersion := &configv1.ClusterVersion{ObjectMeta: metav1.ObjectMeta{Name: "version"}}
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud-provider-config", Namespace: "openshift-config"},
+		Data:       map[string]string{"config": "[Global]\n"},
+	}
+	target := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-cloud-config", Namespace: "openshift-config-managed"},
+		Data:       map[string]string{"cloud.conf": "[Global]\n"},
+	}
+	staleTarget := target.DeepCopy()
+	staleTarget.Data["cloud.conf"] = "[Global]\nold = true\n"
+
+	cases := []struct {
+		name            string
+		managementState operatorv1.ManagementState
+		overrides       string
+		operatorVersion string
+		objects         []metav1.Object
+
+		available   string
+		progressing string
+	}{{
+		name:            "as expected",
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source, target},
+		available:       "AsExpected",
+		progressing:     "AsExpected",
+	}, {
+		name:            "missing objects",
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{featureGate},
+		available:       "RequiredObjectsMissing",
+		progressing:     "AsExpected",
+	}, {
+		name:            "feature gates lag the operator version",
+		operatorVersion: "4.21.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source, target},
+		available:       "AsExpected",
+		progressing:     "FeatureGateStatusUpdating",
+	}, {
+		name:            "kube-cloud-config not yet created",
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source},
+		available:       "AsExpected",
+		progressing:     "KubeCloudConfigUpdating",
+	}, {
+		name:            "kube-cloud-config stale",
+		operatorVersion: "4.21.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source, staleTarget},
+		available:       "AsExpected",
+		progressing:     "FeatureGateStatusUpdatingAndKubeCloudConfigUpdating",
+	}, {
+		name:            "kube-cloud-config not deleted",
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{&configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Status: infra.Status}, featureGate, clusterVersion, target},
+		available:       "AsExpected",
+		progressing:     "KubeCloudConfigUpdating",
+	}, {
+		name:            "kube-cloud-config in a Secret",
+		overrides:       `{"kubeCloudConfig":{"targetKind":"Secret"}}`,
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source},
+		available:       "AsExpected",
+		progressing:     "KubeCloudConfigUnknown",
+	}, {
+		name:            "unparseable overrides",
+		overrides:       `{"kubeCloudConfig":`,
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source, target},
+		available:       "AsExpected",
+		progressing:     "KubeCloudConfigUnknown",
+	}, {
+		name:            "kube-cloud-config source missing",
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, target},
+		available:       "AsExpected",
+		progressing:     "KubeCloudConfigUnknown",
+	}, {
+		name:            "feature gates lag and kube-cloud-config unknown",
+		overrides:       `{"kubeCloudConfig":{"targetKind":"Secret"}}`,
+		operatorVersion: "4.21.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion, source},
+		available:       "AsExpected",
+		progressing:     "FeatureGateStatusUpdating",
+	}, {
+		name:            "unmanaged",
+		managementState: operatorv1.Unmanaged,
+		operatorVersion: "4.21.0",
+		objects:         []metav1.Object{featureGate},
+		available:       "Unmanaged",
+		progressing:     "Unmanaged",
+	}, {
+		name:            "removed",
+		managementState: operatorv1.Removed,
+		operatorVersion: "4.20.0",
+		objects:         []metav1.Object{infra, featureGate, clusterVersion},
+		available:       "Removed",
+		progressing:     "Removed",
+	}, {
+		name:            "unsupported management state",
+		managementState: operatorv1.Force,
+		operatorVersion: "4.20.0",
+		available:       "UnsupportedManagementState",
+		progressing:     "UnsupportedManagementState",
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			infraIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			featureGateIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			clusterVersionIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, obj := range test.objects {
+				var err error
+				switch obj.(type) {
+				case *configv1.Infrastructure:
+					err = infraIndexer.Add(obj)
+				case *configv1.FeatureGate:
+					err = featureGateIndexer.Add(obj)
+				case *configv1.ClusterVersion:
+					err = clusterVersionIndexer.Add(obj)
+				case *corev1.ConfigMap:
+					err = configMapIndexer.Add(obj)
+				}
+				require.NoError(t, err)
+			}
+
+			spec := &operatorv1.OperatorSpec{ManagementState: test.managementState}
+			spec.UnsupportedConfigOverrides.Raw = []byte(test.overrides)
+			operatorClient := v1helpers.NewFakeOperatorClient(spec, &operatorv1.OperatorStatus{}, nil)
+			c := &ConfigOperatorController{
+				operatorClient:         operatorClient,
+				operatorVersion:        test.operatorVersion,
+				infraLister:            configv1listers.NewInfrastructureLister(infraIndexer),
+				featureGateLister:      configv1listers.NewFeatureGateLister(featureGateIndexer),
+				clusterVersionLister:   configv1listers.NewClusterVersionLister(clusterVersionIndexer),
+				userConfigMapLister:    corev1listers.NewConfigMapLister(configMapIndexer).ConfigMaps("openshift-config"),
+				managedConfigMapLister: corev1listers.NewConfigMapLister(configMapIndexer).ConfigMaps("openshift-config-managed"),
+			}
+			syncCtx := factory.NewSyncContext("ConfigOperatorController", events.NewInMemoryRecorder("ConfigOperatorController", clocktesting.NewFakePassiveClock(time.Now())))
+			require.NoError(t, c.sync(context.TODO(), syncCtx))
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			require.NoError(t, err)
+			available := v1helpers.FindOperatorCondition(status.Conditions, "OperatorAvailable")
+			require.NotNil(t, available)
+			assert.Equal(t, test.available, available.Reason)
+			progressing := v1helpers.FindOperatorCondition(status.Conditions, "OperatorProgressing")
+			require.NotNil(t, progressing)
+			assert.Equal(t, test.progressing, progressing.Reason)
+			assert.Nil(t, v1helpers.FindOperatorCondition(status.Conditions, "OperatorUpgradeable"))
+			managementStateDegraded := v1helpers.FindOperatorCondition(status.Conditions, "ManagementStateDegraded")
+			require.NotNil(t, managementStateDegraded)
+			assert.Equal(t, test.managementState == operatorv1.Force, managementStateDegraded.Status == operatorv1.ConditionTrue)
+
+			switch {
+			case test.available == "AsExpected" || test.available == "Removed":
+				assert.Equal(t, operatorv1.ConditionTrue, available.Status)
+			case test.available == "RequiredObjectsMissing":
+				assert.Equal(t, operatorv1.ConditionFalse, available.Status)
+			default:
+				assert.Equal(t, operatorv1.ConditionUnknown, available.Status)
+			}
+			switch {
+			case test.progressing == "AsExpected" || test.progressing == "Removed":
+				assert.Equal(t, operatorv1.ConditionFalse, progressing.Status)
+			case test.progressing == "Unmanaged" || test.progressing == "UnsupportedManagementState" || test.progressing == "KubeCloudConfigUnknown":
+				assert.Equal(t, operatorv1.ConditionUnknown, progressing.Status)
+			default:
+				assert.Equal(t, operatorv1.ConditionTrue, progressing.Status)
+			}
+		})
+	}
+}
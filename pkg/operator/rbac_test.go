@@ -0,0 +1,363 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/api/features"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configfakeclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions"
+	"github.com/openshift/cluster-config-operator/manifests"
+	"github.com/openshift/cluster-config-operator/pkg/operator/health"
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	featuregatelib "github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefakeclient "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+const (
+	operatorServiceAccountNamespace = "openshift-config-operator"
+	operatorServiceAccountName      = "openshift-config-operator"
+)
+
+// serviceAccountRules returns the rules the RBAC manifests grant to the operator's ServiceAccount by namespace, the
+// rules of ClusterRoles bound by ClusterRoleBindings are under "". Manifests marked for deletion and bindings to roles
+// that are not in the manifests, like system:auth-delegator, are ignored.
+func serviceAccountRules(t *testing.T) map[string][]rbacv1.PolicyRule {
+	clusterRoles := map[string][]rbacv1.PolicyRule{}
+	roles := map[string][]rbacv1.PolicyRule{}
+	var clusterRoleBindings []rbacv1.ClusterRoleBinding
+	var roleBindings []rbacv1.RoleBinding
+
+	files, err := fs.Glob(manifests.FS, "*.yaml")
+	require.NoError(t, err)
+	for _, file := range files {
+		content, err := fs.ReadFile(manifests.FS, file)
+		require.NoError(t, err)
+		decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+		for {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); errors.Is(err, io.EOF) {
+				break
+			} else {
+				require.NoError(t, err, file)
+			}
+			var obj metav1.PartialObjectMetadata
+			require.NoError(t, json.Unmarshal(raw, &obj), file)
+			if obj.Annotations["release.openshift.io/delete"] == "true" || obj.APIVersion != rbacv1.SchemeGroupVersion.String() {
+				continue
+			}
+			switch obj.Kind {
+			case "ClusterRole":
+				var role rbacv1.ClusterRole
+				require.NoError(t, json.Unmarshal(raw, &role), file)
+				clusterRoles[role.Name] = role.Rules
+			case "Role":
+				var role rbacv1.Role
+				require.NoError(t, json.Unmarshal(raw, &role), file)
+				roles[role.Namespace+"/"+role.Name] = role.Rules
+			case "ClusterRoleBinding":
+				var binding rbacv1.ClusterRoleBinding
+				require.NoError(t, json.Unmarshal(raw, &binding), file)
+				clusterRoleBindings = append(clusterRoleBindings, binding)
+			case "RoleBinding":
+				var binding rbacv1.RoleBinding
+				require.NoError(t, json.Unmarshal(raw, &binding), file)
+				roleBindings = append(roleBindings, binding)
+			}
+		}
+	}
+
+	rules := map[string][]rbacv1.PolicyRule{}
+	for _, binding := range clusterRoleBindings {
+		if bindsServiceAccount(binding.Subjects) && binding.RoleRef.Kind == "ClusterRole" {
+			rules[""] = append(rules[""], clusterRoles[binding.RoleRef.Name]...)
+		}
+	}
+	for _, binding := range roleBindings {
+		if !bindsServiceAccount(binding.Subjects) {
+			continue
+		}
+		switch binding.RoleRef.Kind {
+		case "ClusterRole":
+			rules[binding.Namespace] = append(rules[binding.Namespace], clusterRoles[binding.RoleRef.Name]...)
+		case "Role":
+			rules[binding.Namespace] = append(rules[binding.Namespace], roles[binding.Namespace+"/"+binding.RoleRef.Name]...)
+		}
+	}
+	return rules
+}
+
+func bindsServiceAccount(subjects []rbacv1.Subject) bool {
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == operatorServiceAccountNamespace && subject.Name == operatorServiceAccountName {
+			return true
+		}
+	}
+	return false
+}
+
+// rbacChecker denies the actions of fake clients that the rules do not allow, and records them.
+type rbacChecker struct {
+	rules map[string][]rbacv1.PolicyRule
+
+	lock   sync.Mutex
+	denied []string
+}
+
+func (c *rbacChecker) react(action ktesting.Action) (bool, runtime.Object, error) {
+	if err := c.check(action); err != nil {
+		return true, nil, err
+	}
+	return false, nil, nil
+}
+
+func (c *rbacChecker) reactWatch(action ktesting.Action) (bool, watch.Interface, error) {
+	if err := c.check(action); err != nil {
+		return true, nil, err
+	}
+	return false, nil, nil
+}
+
+func (c *rbacChecker) check(action ktesting.Action) error {
+	verb := action.GetVerb()
+	if verb == "delete-collection" {
+		verb = "deletecollection"
+	}
+	gvr := action.GetResource()
+	resource := gvr.Resource
+	if action.GetSubresource() != "" {
+		resource += "/" + action.GetSubresource()
+	}
+	name := actionName(action)
+
+	for _, namespace := range []string{"", action.GetNamespace()} {
+		for _, rule := range c.rules[namespace] {
+			if ruleAllows(rule, verb, gvr.Group, resource, name) {
+				return nil
+			}
+		}
+	}
+
+	denied := fmt.Sprintf("%s %s %q in namespace %q", verb, schema.GroupResource{Group: gvr.Group, Resource: resource}, name, action.GetNamespace())
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !slices.Contains(c.denied, denied) {
+		c.denied = append(c.denied, denied)
+	}
+	return apierrors.NewForbidden(gvr.GroupResource(), name, fmt.Errorf("%s is not allowed by the RBAC manifests", verb))
+}
+
+func (c *rbacChecker) deniedActions() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return slices.Clone(c.denied)
+}
+
+// actionName returns the name of the object of action, empty when the action is not about a single named object.
+func actionName(action ktesting.Action) string {
+	switch action.GetVerb() {
+	case "get":
+		return action.(ktesting.GetAction).GetName()
+	case "delete":
+		return action.(ktesting.DeleteAction).GetName()
+	case "patch":
+		return action.(ktesting.PatchAction).GetName()
+	case "update":
+		if obj, err := meta.Accessor(action.(ktesting.UpdateAction).GetObject()); err == nil {
+			return obj.GetName()
+		}
+	}
+	return ""
+}
+
+// ruleAllows returns true if rule allows verb on the named resource of group, following the RBAC authorizer.
+func ruleAllows(rule rbacv1.PolicyRule, verb, group, resource, name string) bool {
+	matches := func(values []string, value string) bool {
+		return slices.Contains(values, rbacv1.ResourceAll) || slices.Contains(values, value)
+	}
+	if !matches(rule.Verbs, verb) || !matches(rule.APIGroups, group) || !matches(rule.Resources, resource) {
+		return false
+	}
+	return len(rule.ResourceNames) == 0 || (name != "" && slices.Contains(rule.ResourceNames, name))
+}
+
+func Test_rbac_ruleAllows(t *testing.T) {
+	rule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"kube-cloud-config"}, Verbs: []string{"update"}}
+	assert.True(t, ruleAllows(rule, "update", "", "configmaps", "kube-cloud-config"))
+	assert.False(t, ruleAllows(rule, "update", "", "configmaps", "other"))
+	assert.False(t, ruleAllows(rule, "update", "", "configmaps", ""))
+	assert.False(t, ruleAllows(rule, "update", "", "configmaps/status", "kube-cloud-config"))
+	assert.False(t, ruleAllows(rule, "delete", "", "configmaps", "kube-cloud-config"))
+	assert.False(t, ruleAllows(rule, "update", "apps", "configmaps", "kube-cloud-config"))
+}
+
+func Test_rbac_operatorRoles(t *testing.T) {
+	rules := serviceAccountRules(t)
+	require.NotEmpty(t, rules[""], "no ClusterRole bound to the operator")
+	for namespace, namespaceRules := range rules {
+		for _, rule := range namespaceRules {
+			for _, values := range [][]string{rule.Verbs, rule.APIGroups, rule.Resources} {
+				assert.NotContains(t, values, rbacv1.ResourceAll, "wildcard rule %v in namespace %q", rule, namespace)
+			}
+		}
+	}
+
+	// the generic operator client is not backed by a fake clientset, these are the calls it makes
+	checker := &rbacChecker{rules: rules}
+	for _, action := range []ktesting.Action{
+		ktesting.NewRootGetAction(operatorv1.GroupVersion.WithResource("configs"), "cluster"),
+		ktesting.NewRootListAction(operatorv1.GroupVersion.WithResource("configs"), operatorv1.GroupVersion.WithKind("Config"), metav1.ListOptions{}),
+		ktesting.NewRootWatchAction(operatorv1.GroupVersion.WithResource("configs"), metav1.ListOptions{}),
+		ktesting.NewRootUpdateAction(operatorv1.GroupVersion.WithResource("configs"), &operatorv1.Config{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}),
+		ktesting.NewRootUpdateSubresourceAction(operatorv1.GroupVersion.WithResource("configs"), "status", &operatorv1.Config{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}),
+		ktesting.NewRootPatchAction(operatorv1.GroupVersion.WithResource("configs"), "cluster", "application/apply-patch+yaml", nil),
+		ktesting.NewRootPatchSubresourceAction(operatorv1.GroupVersion.WithResource("configs"), "cluster", "application/apply-patch+yaml", nil, "status"),
+	} {
+		assert.NoError(t, checker.check(action))
+	}
+}
+
+// Test_rbac_controllers syncs every controller of the operator, managed and removed, against fake clients that only
+// allow what the RBAC manifests grant to the operator. A controller making a new API call needs a role update.
+func Test_rbac_controllers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := &rbacChecker{rules: serviceAccountRules(t)}
+	kubeClient := kubefakeclient.NewClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: operatorclient.GlobalUserSpecifiedConfigNamespace, Name: "cloud-provider-config"},
+			Data:       map[string]string{"config": "[Global]\n"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cluster-config-v1"},
+			Data:       map[string]string{"install-config": "platform:\n  aws:\n    region: us-east-1\n"},
+		},
+	)
+	kubeClient.PrependReactor("*", "*", checker.react)
+	kubeClient.PrependWatchReactor("*", checker.reactWatch)
+	configClient := configfakeclient.NewClientset(
+		&configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: configv1.InfrastructureSpec{
+				CloudConfig: configv1.ConfigMapFileReference{Name: "cloud-provider-config", Key: "config"},
+				PlatformSpec: configv1.PlatformSpec{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformSpec{
+					ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "https://ec2.example.com"}},
+				}},
+			},
+			Status: configv1.InfrastructureStatus{
+				// status.platform is set by the InfrastructureNormalizerController
+				PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType, AWS: &configv1.AWSPlatformStatus{Region: "us-east-1"}},
+			},
+		},
+		&configv1.FeatureGate{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			// migrated by the FeatureSetMigrationController
+			Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{FeatureSet: "LatencySensitive"}},
+		},
+		&configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.99.0"}},
+		},
+		&configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: "config-operator"}},
+	)
+	configClient.PrependReactor("*", "*", checker.react)
+	configClient.PrependWatchReactor("*", checker.reactWatch)
+
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{ManagementState: operatorv1.Managed}, &operatorv1.OperatorStatus{}, nil)
+	configInformers := configv1informers.NewSharedInformerFactory(configClient, 0)
+	kubeInformersForNamespaces := v1helpers.NewKubeInformersForNamespaces(kubeClient, kubeInformerNamespaces...)
+	clock := clocktesting.NewFakeClock(time.Now())
+	recorder := events.NewInMemoryRecorder("rbac-test", clock)
+
+	operator := &OperatorOptions{OperatorVersion: "4.99.0", AuthoritativeFeatureGateDir: t.TempDir()}
+	_, err := operator.getFeatureGateMappingFromDisk(ctx, configClient)
+	assert.EqualError(t, err, "featuregates not located")
+	versionRecorder, err := newVersionRecorder(ctx, configClient, operator.OperatorVersion)
+	require.NoError(t, err)
+
+	readiness := health.NewReadiness(clock)
+	controllers := newControllers(operator.OperatorVersion,
+		// the FeatureGateController syncs before the featureSet is migrated
+		map[configv1.FeatureSet]*features.FeatureGateEnabledDisabled{configv1.Default: {}, "LatencySensitive": {}},
+		// the kube-cloud-config Secrets are watched, so that the permissions of the Secret mode are checked
+		operatorClient, kubeClient, configClient, configInformers, kubeInformersForNamespaces, true,
+		featuregatelib.NewHardcodedFeatureGateAccess(nil, nil), versionRecorder, readiness, clock, recorder)
+	configInformers.Start(ctx.Done())
+	kubeInformersForNamespaces.Start(ctx.Done())
+	// informers whose list or watch is denied never sync
+	cacheSyncCtx, cacheSyncCancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cacheSyncCancel()
+	for informerType, synced := range configInformers.WaitForCacheSync(cacheSyncCtx.Done()) {
+		require.True(t, synced, "informer for %v not synced, denied API calls: %v", informerType, checker.deniedActions())
+	}
+	for _, namespace := range kubeInformersForNamespaces.Namespaces().UnsortedList() {
+		for informerType, synced := range kubeInformersForNamespaces.InformersFor(namespace).WaitForCacheSync(cacheSyncCtx.Done()) {
+			require.True(t, synced, "informer for %v in namespace %q not synced, denied API calls: %v", informerType, namespace, checker.deniedActions())
+		}
+	}
+
+	all := append([]factory.Controller{controllers.featureGate}, append(controllers.ungated, controllers.gated...)...)
+	syncAll := func() {
+		for _, c := range all {
+			if err := c.Sync(ctx, factory.NewSyncContext(c.Name(), recorder)); err != nil {
+				// only the permissions are checked, the fake cluster is not complete
+				t.Logf("%s: %v", c.Name(), err)
+			}
+		}
+	}
+
+	// every controller records a heartbeat when it syncs
+	clock.Step(10 * time.Minute)
+	syncAll()
+	for _, check := range readiness.HealthChecks() {
+		if check.Name() == "controller-syncs" {
+			assert.NoError(t, check.Check(httptest.NewRequest("GET", "/readyz", nil)))
+		}
+	}
+	_, err = kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get(ctx, "kube-cloud-config", metav1.GetOptions{})
+	assert.NoError(t, err, "the kube-cloud-config was not written")
+
+	_, _, resourceVersion, err := operatorClient.GetOperatorState()
+	require.NoError(t, err)
+	_, _, err = operatorClient.UpdateOperatorSpec(ctx, resourceVersion, &operatorv1.OperatorSpec{ManagementState: operatorv1.Removed})
+	require.NoError(t, err)
+	// the informers have to observe the kube-cloud-config before it is deleted
+	require.Eventually(t, func() bool {
+		_, err := kubeInformersForNamespaces.ConfigMapLister().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get("kube-cloud-config")
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+	syncAll()
+	_, err = kubeClient.CoreV1().ConfigMaps(operatorclient.GlobalMachineSpecifiedConfigNamespace).Get(ctx, "kube-cloud-config", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the kube-cloud-config was not deleted")
+
+	assert.Empty(t, checker.deniedActions(), "API calls not allowed by the RBAC manifests")
+}
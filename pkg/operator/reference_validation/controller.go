@@ -0,0 +1,197 @@
+package referencevalidation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	applyoperatorv1 "github.com/openshift/client-go/operator/applyconfigurations/operator/v1"
+	"github.com/openshift/cluster-config-operator/pkg/operator/controllergate"
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// InvalidReferencesConditionType is the operator condition reporting the references to ConfigMaps and Secrets in
+// openshift-config that are missing or whose data is not what the referencing object expects.
+const InvalidReferencesConditionType = "ReferenceValidationControllerInvalidReferences"
+
+// ReferenceValidationController resolves the references of the proxies, apiservers, oauths and images of
+// config.openshift.io to ConfigMaps and Secrets in openshift-config, and validates their data: the referenced keys must
+// exist, and CA bundles, certificates and private keys must be PEM-encoded. Invalid references are reported in the
+// InvalidReferencesConditionType condition, and as a warning event when they are first seen, so that they are noticed
+// before the operators consuming them fail.
+// The controller only reads the cluster, it runs regardless of spec.managementState.
+type ReferenceValidationController struct {
+	operatorClient v1helpers.OperatorClient
+
+	proxyLister     configv1listers.ProxyLister
+	apiServerLister configv1listers.APIServerLister
+	oauthLister     configv1listers.OAuthLister
+	imageLister     configv1listers.ImageLister
+	configMapLister corev1listers.ConfigMapNamespaceLister
+	secretLister    corev1listers.SecretNamespaceLister
+
+	// reported are the invalid references of the last sync, events are only emitted for new ones.
+	// The controller syncs with a single worker.
+	reported sets.Set[string]
+}
+
+// NewController returns a ReferenceValidationController
+func NewController(operatorClient v1helpers.OperatorClient,
+	proxyInformer configv1informers.ProxyInformer,
+	apiServerInformer configv1informers.APIServerInformer,
+	oauthInformer configv1informers.OAuthInformer,
+	imageInformer configv1informers.ImageInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
+	secretInformer corev1informers.SecretInformer,
+	recorder events.Recorder) factory.Controller {
+	c := &ReferenceValidationController{
+		operatorClient:  operatorClient,
+		proxyLister:     proxyInformer.Lister(),
+		apiServerLister: apiServerInformer.Lister(),
+		oauthLister:     oauthInformer.Lister(),
+		imageLister:     imageInformer.Lister(),
+		configMapLister: configMapInformer.Lister().ConfigMaps(operatorclient.GlobalUserSpecifiedConfigNamespace),
+		secretLister:    secretInformer.Lister().Secrets(operatorclient.GlobalUserSpecifiedConfigNamespace),
+		reported:        sets.New[string](),
+	}
+	return factory.New().
+		WithInformers(
+			operatorClient.Informer(),
+			proxyInformer.Informer(),
+			apiServerInformer.Informer(),
+			oauthInformer.Informer(),
+			imageInformer.Informer(),
+			configMapInformer.Informer(),
+			secretInformer.Informer(),
+		).
+		WithSync(controllergate.Guard("ReferenceValidationController", operatorClient, c.sync)).
+		WithSyncDegradedOnError(operatorClient).
+		ResyncEvery(time.Minute).
+		ToController("ReferenceValidationController", recorder)
+}
+
+func (c *ReferenceValidationController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	refs, err := c.references()
+	if err != nil {
+		return err
+	}
+
+	var invalid []string
+	for _, ref := range refs {
+		problem, err := c.validate(ref)
+		if err != nil {
+			return err
+		}
+		if len(problem) > 0 {
+			invalid = append(invalid, problem)
+		}
+	}
+
+	condition := applyoperatorv1.OperatorCondition().WithType(InvalidReferencesConditionType)
+	if len(invalid) == 0 {
+		condition = condition.WithStatus(operatorv1.ConditionFalse).WithReason("AsExpected")
+	} else {
+		condition = condition.WithStatus(operatorv1.ConditionTrue).WithReason("InvalidReferences").
+			WithMessage(fmt.Sprintf("invalid references to %s:\n%s", operatorclient.GlobalUserSpecifiedConfigNamespace, strings.Join(invalid, "\n")))
+	}
+	if err := c.operatorClient.ApplyOperatorStatus(ctx,
+		factory.ControllerFieldManager("ReferenceValidationController", "invalid-references"),
+		applyoperatorv1.OperatorStatus().WithConditions(condition)); err != nil {
+		return err
+	}
+
+	for _, problem := range invalid {
+		if !c.reported.Has(problem) {
+			syncCtx.Recorder().Warningf("InvalidConfigReference", "%s", problem)
+		}
+	}
+	c.reported = sets.New(invalid...)
+	return nil
+}
+
+// references returns the references to openshift-config of all objects watched by the controller.
+func (c *ReferenceValidationController) references() (references, error) {
+	var refs references
+	proxies, err := c.proxyLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, proxy := range proxies {
+		refs.addProxy(proxy)
+	}
+	apiServers, err := c.apiServerLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, apiServer := range apiServers {
+		refs.addAPIServer(apiServer)
+	}
+	oauths, err := c.oauthLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, oauth := range oauths {
+		refs.addOAuth(oauth)
+	}
+	images, err := c.imageLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		refs.addImage(image)
+	}
+	return refs, nil
+}
+
+// validate resolves ref and returns a description of the problem naming the referrer, or an empty string if ref is
+// valid.
+func (c *ReferenceValidationController) validate(ref reference) (string, error) {
+	data := map[string][]byte{}
+	switch ref.kind {
+	case configMapKind:
+		cm, err := c.configMapLister.Get(ref.name)
+		if apierrors.IsNotFound(err) {
+			return ref.problem("not found"), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range cm.BinaryData {
+			data[k] = v
+		}
+	case secretKind:
+		secret, err := c.secretLister.Get(ref.name)
+		if apierrors.IsNotFound(err) {
+			return ref.problem("not found"), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		data = secret.Data
+	}
+	if err := ref.validate(data); err != nil {
+		return ref.problem(err.Error()), nil
+	}
+	return "", nil
+}
+
+// problem returns the description of a problem of ref, e.g.
+// `proxies.config.openshift.io/cluster spec.trustedCA.name: ConfigMap openshift-config/user-ca-bundle: not found`.
+func (ref reference) problem(detail string) string {
+	return fmt.Sprintf("%s %s: %s %s/%s: %s", ref.referrer, ref.fldPath, ref.kind, operatorclient.GlobalUserSpecifiedConfigNamespace, ref.name, detail)
+}
@@ -0,0 +1,254 @@
+package referencevalidation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// testKeyPair returns a PEM-encoded self-signed certificate and its private key.
+func testKeyPair(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// newTestController returns a ReferenceValidationController with listers for objects, keyed by their type.
+func newTestController(t *testing.T, operatorClient v1helpers.OperatorClient, objects ...interface{}) (*ReferenceValidationController, map[reflect.Type]cache.Indexer) {
+	indexers := map[reflect.Type]cache.Indexer{}
+	for _, obj := range []interface{}{&configv1.Proxy{}, &configv1.APIServer{}, &configv1.OAuth{}, &configv1.Image{}, &corev1.ConfigMap{}, &corev1.Secret{}} {
+		indexers[reflect.TypeOf(obj)] = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	}
+	for _, obj := range objects {
+		require.NoError(t, indexers[reflect.TypeOf(obj)].Add(obj))
+	}
+	return &ReferenceValidationController{
+		operatorClient:  operatorClient,
+		proxyLister:     configv1listers.NewProxyLister(indexers[reflect.TypeOf(&configv1.Proxy{})]),
+		apiServerLister: configv1listers.NewAPIServerLister(indexers[reflect.TypeOf(&configv1.APIServer{})]),
+		oauthLister:     configv1listers.NewOAuthLister(indexers[reflect.TypeOf(&configv1.OAuth{})]),
+		imageLister:     configv1listers.NewImageLister(indexers[reflect.TypeOf(&configv1.Image{})]),
+		configMapLister: corev1listers.NewConfigMapLister(indexers[reflect.TypeOf(&corev1.ConfigMap{})]).ConfigMaps("openshift-config"),
+		secretLister:    corev1listers.NewSecretLister(indexers[reflect.TypeOf(&corev1.Secret{})]).Secrets("openshift-config"),
+		reported:        sets.New[string](),
+	}, indexers
+}
+
+func Test_sync(t *testing.T) {
+	certPEM, keyPEM := testKeyPair(t)
+	_, otherKeyPEM := testKeyPair(t)
+
+	cases := []struct {
+		name    string
+		objects []interface{}
+
+		invalid []string
+	}{{
+		name: "no references",
+		objects: []interface{}{
+			&configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+			&configv1.OAuth{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
+		},
+	}, {
+		name: "valid references",
+		objects: []interface{}{
+			&configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.ProxySpec{TrustedCA: configv1.ConfigMapNameReference{Name: "user-ca-bundle"}}},
+			&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.APIServerSpec{ServingCerts: configv1.APIServerServingCerts{NamedCertificates: []configv1.APIServerNamedServingCert{
+					{ServingCertificate: configv1.SecretNameReference{Name: "api-cert"}},
+				}}}},
+			&configv1.OAuth{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.OAuthSpec{IdentityProviders: []configv1.IdentityProvider{{
+					Name: "htpasswd",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						HTPasswd: &configv1.HTPasswdIdentityProvider{FileData: configv1.SecretNameReference{Name: "htpass-secret"}},
+					},
+				}, {
+					Name: "keystone",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						Keystone: &configv1.KeystoneIdentityProvider{OAuthRemoteConnectionInfo: configv1.OAuthRemoteConnectionInfo{
+							TLSClientCert: configv1.SecretNameReference{Name: "api-cert"},
+							TLSClientKey:  configv1.SecretNameReference{Name: "api-cert"},
+						}},
+					},
+				}}}},
+			&configv1.Image{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.ImageSpec{AdditionalTrustedCA: configv1.ConfigMapNameReference{Name: "registry-cas"}}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "user-ca-bundle"},
+				Data: map[string]string{"ca-bundle.crt": string(certPEM)}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "registry-cas"},
+				Data: map[string]string{"registry.example.com": string(certPEM), "registry.example.com..5000": string(certPEM)}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "api-cert"},
+				Data: map[string][]byte{"tls.crt": certPEM, "tls.key": keyPEM}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "htpass-secret"},
+				Data: map[string][]byte{"htpasswd": []byte("user:$2y$05$hash")}},
+		},
+	}, {
+		name: "missing objects",
+		objects: []interface{}{
+			&configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.ProxySpec{TrustedCA: configv1.ConfigMapNameReference{Name: "user-ca-bundle"}}},
+			&configv1.OAuth{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.OAuthSpec{Templates: configv1.OAuthTemplates{Login: configv1.SecretNameReference{Name: "login-template"}}}},
+			// in another namespace
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config-managed", Name: "user-ca-bundle"},
+				Data: map[string]string{"ca-bundle.crt": string(certPEM)}},
+		},
+		invalid: []string{
+			"proxies.config.openshift.io/cluster spec.trustedCA.name: ConfigMap openshift-config/user-ca-bundle: not found",
+			"oauths.config.openshift.io/cluster spec.templates.login.name: Secret openshift-config/login-template: not found",
+		},
+	}, {
+		name: "missing keys",
+		objects: []interface{}{
+			&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.APIServerSpec{ClientCA: configv1.ConfigMapNameReference{Name: "client-ca"}}},
+			&configv1.OAuth{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.OAuthSpec{IdentityProviders: []configv1.IdentityProvider{{
+					Name: "ldap",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						LDAP: &configv1.LDAPIdentityProvider{BindPassword: configv1.SecretNameReference{Name: "ldap-secret"}},
+					},
+				}}}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "client-ca"},
+				Data: map[string]string{"ca.crt": string(certPEM)}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "ldap-secret"},
+				Data: map[string][]byte{"password": []byte("secret")}},
+		},
+		invalid: []string{
+			`apiservers.config.openshift.io/cluster spec.clientCA.name: ConfigMap openshift-config/client-ca: key "ca-bundle.crt" not found`,
+			`oauths.config.openshift.io/cluster spec.identityProviders[0].ldap.bindPassword.name: Secret openshift-config/ldap-secret: key "bindPassword" not found`,
+		},
+	}, {
+		name: "malformed data",
+		objects: []interface{}{
+			&configv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.APIServerSpec{ServingCerts: configv1.APIServerServingCerts{NamedCertificates: []configv1.APIServerNamedServingCert{
+					{ServingCertificate: configv1.SecretNameReference{Name: "api-cert"}},
+				}}}},
+			&configv1.OAuth{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.OAuthSpec{IdentityProviders: []configv1.IdentityProvider{{
+					Name: "github",
+					IdentityProviderConfig: configv1.IdentityProviderConfig{
+						GitHub: &configv1.GitHubIdentityProvider{
+							ClientSecret: configv1.SecretNameReference{Name: "github-secret"},
+							CA:           configv1.ConfigMapNameReference{Name: "github-ca"},
+						},
+					},
+				}}}},
+			&configv1.Image{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec: configv1.ImageSpec{AdditionalTrustedCA: configv1.ConfigMapNameReference{Name: "registry-cas"}}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "api-cert"},
+				Data: map[string][]byte{"tls.crt": certPEM, "tls.key": otherKeyPEM}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "github-secret"},
+				Data: map[string][]byte{"clientSecret": []byte("secret")}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "github-ca"},
+				Data: map[string]string{"ca.crt": "not a certificate"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "registry-cas"},
+				Data: map[string]string{"a.example.com": string(certPEM), "b.example.com": string(keyPEM)}},
+		},
+		invalid: []string{
+			`apiservers.config.openshift.io/cluster spec.servingCerts.namedCertificates[0].servingCertificate.name: Secret openshift-config/api-cert: ` +
+				`keys "tls.crt" and "tls.key" do not hold a PEM-encoded certificate and its private key: tls: private key does not match public key`,
+			`oauths.config.openshift.io/cluster spec.identityProviders[0].github.ca.name: ConfigMap openshift-config/github-ca: ` +
+				`key "ca.crt" does not hold PEM-encoded certificates: data does not contain any valid RSA or ECDSA certificates`,
+			`images.config.openshift.io/cluster spec.additionalTrustedCA.name: ConfigMap openshift-config/registry-cas: ` +
+				`key "b.example.com" does not hold PEM-encoded certificates: data does not contain any valid RSA or ECDSA certificates`,
+		},
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+			recorder := events.NewInMemoryRecorder("ReferenceValidationController", clocktesting.NewFakePassiveClock(time.Now()))
+			ctrl, _ := newTestController(t, operatorClient, test.objects...)
+			require.NoError(t, ctrl.sync(context.TODO(), factory.NewSyncContext("ReferenceValidationController", recorder)))
+
+			_, status, _, err := operatorClient.GetOperatorState()
+			require.NoError(t, err)
+			condition := v1helpers.FindOperatorCondition(status.Conditions, InvalidReferencesConditionType)
+			require.NotNil(t, condition)
+			var warnings []string
+			for _, event := range recorder.Events() {
+				assert.Equal(t, "InvalidConfigReference", event.Reason)
+				warnings = append(warnings, event.Message)
+			}
+			if len(test.invalid) == 0 {
+				assert.Equal(t, operatorv1.ConditionFalse, condition.Status)
+				assert.Equal(t, "AsExpected", condition.Reason)
+				assert.Empty(t, warnings)
+				return
+			}
+			assert.Equal(t, operatorv1.ConditionTrue, condition.Status)
+			assert.Equal(t, "InvalidReferences", condition.Reason)
+			assert.ElementsMatch(t, test.invalid, warnings)
+			for _, invalid := range test.invalid {
+				assert.Contains(t, condition.Message, invalid)
+			}
+		})
+	}
+}
+
+func Test_sync_eventsForNewProblems(t *testing.T) {
+	operatorClient := v1helpers.NewFakeOperatorClient(&operatorv1.OperatorSpec{}, &operatorv1.OperatorStatus{}, nil)
+	recorder := events.NewInMemoryRecorder("ReferenceValidationController", clocktesting.NewFakePassiveClock(time.Now()))
+	syncCtx := factory.NewSyncContext("ReferenceValidationController", recorder)
+	ctrl, indexers := newTestController(t, operatorClient, &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.ProxySpec{TrustedCA: configv1.ConfigMapNameReference{Name: "user-ca-bundle"}}})
+	configMaps := indexers[reflect.TypeOf(&corev1.ConfigMap{})]
+
+	// a problem is reported once while it persists
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	assert.Len(t, recorder.Events(), 1)
+
+	// and again when it comes back after it was fixed
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "user-ca-bundle"},
+		Data: map[string]string{"ca-bundle.crt": "not a certificate"}}
+	require.NoError(t, configMaps.Add(cm))
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	require.NoError(t, configMaps.Delete(cm))
+	require.NoError(t, ctrl.sync(context.TODO(), syncCtx))
+	var messages []string
+	for _, event := range recorder.Events() {
+		messages = append(messages, event.Message)
+	}
+	assert.Equal(t, []string{
+		"proxies.config.openshift.io/cluster spec.trustedCA.name: ConfigMap openshift-config/user-ca-bundle: not found",
+		`proxies.config.openshift.io/cluster spec.trustedCA.name: ConfigMap openshift-config/user-ca-bundle: key "ca-bundle.crt" does not hold PEM-encoded certificates: data does not contain any valid RSA or ECDSA certificates`,
+		"proxies.config.openshift.io/cluster spec.trustedCA.name: ConfigMap openshift-config/user-ca-bundle: not found",
+	}, messages)
+}
@@ -0,0 +1,186 @@
+package referencevalidation
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	configMapKind = "ConfigMap"
+	secretKind    = "Secret"
+
+	// caBundleKey is the key of the CA bundle of the ConfigMaps referenced by the proxy and the apiserver.
+	caBundleKey = "ca-bundle.crt"
+	// caKey is the key of the CA bundle of the ConfigMaps referenced by the identity providers.
+	caKey = "ca.crt"
+)
+
+// reference is a reference of a config.openshift.io object to a ConfigMap or Secret in openshift-config.
+type reference struct {
+	// referrer names the referencing object, e.g. oauths.config.openshift.io/cluster.
+	referrer string
+	// fldPath is the path of the name of the referenced object in the referrer.
+	fldPath *field.Path
+	kind    string
+	name    string
+	// validate returns an error if the data of the referenced object is not what the referrer expects.
+	validate func(data map[string][]byte) error
+}
+
+// references collects the references of the objects it is given. References with an empty name are optional and
+// not set, they are skipped.
+type references []reference
+
+func (refs *references) add(referrer string, fldPath *field.Path, kind, name string, validate func(map[string][]byte) error) {
+	if len(name) == 0 {
+		return
+	}
+	*refs = append(*refs, reference{referrer: referrer, fldPath: fldPath, kind: kind, name: name, validate: validate})
+}
+
+func referrerName(resource, name string) string {
+	return fmt.Sprintf("%s.%s/%s", resource, configv1.GroupName, name)
+}
+
+// addProxy adds the references of proxy, see configv1.ProxySpec.
+func (refs *references) addProxy(proxy *configv1.Proxy) {
+	referrer := referrerName("proxies", proxy.Name)
+	refs.add(referrer, field.NewPath("spec", "trustedCA", "name"), configMapKind, proxy.Spec.TrustedCA.Name, pemCertificates(caBundleKey))
+}
+
+// addAPIServer adds the references of apiServer, see configv1.APIServerSpec.
+func (refs *references) addAPIServer(apiServer *configv1.APIServer) {
+	referrer := referrerName("apiservers", apiServer.Name)
+	specPath := field.NewPath("spec")
+	refs.add(referrer, specPath.Child("clientCA", "name"), configMapKind, apiServer.Spec.ClientCA.Name, pemCertificates(caBundleKey))
+	for i, namedCertificate := range apiServer.Spec.ServingCerts.NamedCertificates {
+		refs.add(referrer, specPath.Child("servingCerts", "namedCertificates").Index(i).Child("servingCertificate", "name"),
+			secretKind, namedCertificate.ServingCertificate.Name, keyPair(corev1.TLSCertKey, corev1.TLSPrivateKeyKey))
+	}
+}
+
+// addImage adds the references of image, see configv1.ImageSpec. Every key of the additional trusted CA is a
+// registry hostname with the CA bundle of the registry.
+func (refs *references) addImage(image *configv1.Image) {
+	referrer := referrerName("images", image.Name)
+	refs.add(referrer, field.NewPath("spec", "additionalTrustedCA", "name"), configMapKind, image.Spec.AdditionalTrustedCA.Name, allPEMCertificates)
+}
+
+// addOAuth adds the references of the templates and identity providers of oauth, see configv1.OAuthSpec.
+func (refs *references) addOAuth(oauth *configv1.OAuth) {
+	referrer := referrerName("oauths", oauth.Name)
+	specPath := field.NewPath("spec")
+
+	templatesPath := specPath.Child("templates")
+	refs.add(referrer, templatesPath.Child("login", "name"), secretKind, oauth.Spec.Templates.Login.Name, hasKey(configv1.LoginTemplateKey))
+	refs.add(referrer, templatesPath.Child("providerSelection", "name"), secretKind, oauth.Spec.Templates.ProviderSelection.Name, hasKey(configv1.ProviderSelectionTemplateKey))
+	refs.add(referrer, templatesPath.Child("error", "name"), secretKind, oauth.Spec.Templates.Error.Name, hasKey(configv1.ErrorsTemplateKey))
+
+	for i, idp := range oauth.Spec.IdentityProviders {
+		idpPath := specPath.Child("identityProviders").Index(i)
+		switch {
+		case idp.BasicAuth != nil:
+			refs.addRemoteConnectionInfo(referrer, idpPath.Child("basicAuth"), idp.BasicAuth.OAuthRemoteConnectionInfo)
+		case idp.GitHub != nil:
+			fldPath := idpPath.Child("github")
+			refs.add(referrer, fldPath.Child("clientSecret", "name"), secretKind, idp.GitHub.ClientSecret.Name, hasKey(configv1.ClientSecretKey))
+			refs.add(referrer, fldPath.Child("ca", "name"), configMapKind, idp.GitHub.CA.Name, pemCertificates(caKey))
+		case idp.GitLab != nil:
+			fldPath := idpPath.Child("gitlab")
+			refs.add(referrer, fldPath.Child("clientSecret", "name"), secretKind, idp.GitLab.ClientSecret.Name, hasKey(configv1.ClientSecretKey))
+			refs.add(referrer, fldPath.Child("ca", "name"), configMapKind, idp.GitLab.CA.Name, pemCertificates(caKey))
+		case idp.Google != nil:
+			refs.add(referrer, idpPath.Child("google", "clientSecret", "name"), secretKind, idp.Google.ClientSecret.Name, hasKey(configv1.ClientSecretKey))
+		case idp.HTPasswd != nil:
+			refs.add(referrer, idpPath.Child("htpasswd", "fileData", "name"), secretKind, idp.HTPasswd.FileData.Name, hasKey(configv1.HTPasswdDataKey))
+		case idp.Keystone != nil:
+			refs.addRemoteConnectionInfo(referrer, idpPath.Child("keystone"), idp.Keystone.OAuthRemoteConnectionInfo)
+		case idp.LDAP != nil:
+			fldPath := idpPath.Child("ldap")
+			refs.add(referrer, fldPath.Child("bindPassword", "name"), secretKind, idp.LDAP.BindPassword.Name, hasKey(configv1.BindPasswordKey))
+			refs.add(referrer, fldPath.Child("ca", "name"), configMapKind, idp.LDAP.CA.Name, pemCertificates(caKey))
+		case idp.OpenID != nil:
+			fldPath := idpPath.Child("openID")
+			refs.add(referrer, fldPath.Child("clientSecret", "name"), secretKind, idp.OpenID.ClientSecret.Name, hasKey(configv1.ClientSecretKey))
+			refs.add(referrer, fldPath.Child("ca", "name"), configMapKind, idp.OpenID.CA.Name, pemCertificates(caKey))
+		case idp.RequestHeader != nil:
+			refs.add(referrer, idpPath.Child("requestHeader", "ca", "name"), configMapKind, idp.RequestHeader.ClientCA.Name, pemCertificates(caKey))
+		}
+	}
+}
+
+// addRemoteConnectionInfo adds the references of the connection info of the basic auth and keystone identity providers.
+func (refs *references) addRemoteConnectionInfo(referrer string, fldPath *field.Path, info configv1.OAuthRemoteConnectionInfo) {
+	refs.add(referrer, fldPath.Child("ca", "name"), configMapKind, info.CA.Name, pemCertificates(caKey))
+	refs.add(referrer, fldPath.Child("tlsClientCert", "name"), secretKind, info.TLSClientCert.Name, pemCertificates(corev1.TLSCertKey))
+	refs.add(referrer, fldPath.Child("tlsClientKey", "name"), secretKind, info.TLSClientKey.Name, pemPrivateKey(corev1.TLSPrivateKeyKey))
+}
+
+// hasKey returns a validation requiring a non-empty value at key.
+func hasKey(key string) func(map[string][]byte) error {
+	return func(data map[string][]byte) error {
+		if len(data[key]) == 0 {
+			return fmt.Errorf("key %q not found", key)
+		}
+		return nil
+	}
+}
+
+// pemCertificates returns a validation requiring PEM-encoded certificates at key.
+func pemCertificates(key string) func(map[string][]byte) error {
+	return func(data map[string][]byte) error {
+		if err := hasKey(key)(data); err != nil {
+			return err
+		}
+		if _, err := cert.ParseCertsPEM(data[key]); err != nil {
+			return fmt.Errorf("key %q does not hold PEM-encoded certificates: %w", key, err)
+		}
+		return nil
+	}
+}
+
+// pemPrivateKey returns a validation requiring a PEM-encoded private key at key.
+func pemPrivateKey(key string) func(map[string][]byte) error {
+	return func(data map[string][]byte) error {
+		if err := hasKey(key)(data); err != nil {
+			return err
+		}
+		if _, err := keyutil.ParsePrivateKeyPEM(data[key]); err != nil {
+			return fmt.Errorf("key %q does not hold a PEM-encoded private key: %w", key, err)
+		}
+		return nil
+	}
+}
+
+// keyPair returns a validation requiring a PEM-encoded certificate at certKey and its private key at keyKey.
+func keyPair(certKey, keyKey string) func(map[string][]byte) error {
+	return func(data map[string][]byte) error {
+		if err := hasKey(certKey)(data); err != nil {
+			return err
+		}
+		if err := hasKey(keyKey)(data); err != nil {
+			return err
+		}
+		if _, err := tls.X509KeyPair(data[certKey], data[keyKey]); err != nil {
+			return fmt.Errorf("keys %q and %q do not hold a PEM-encoded certificate and its private key: %w", certKey, keyKey, err)
+		}
+		return nil
+	}
+}
+
+// allPEMCertificates requires PEM-encoded certificates at every key.
+func allPEMCertificates(data map[string][]byte) error {
+	for _, key := range sets.List(sets.KeySet(data)) {
+		if err := pemCertificates(key)(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
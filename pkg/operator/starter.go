@@ -1,8 +1,10 @@
+// Package operator starts the controllers of the cluster-config-operator. The operator is not bound to cluster-admin,
+// the roles in manifests/0000_10_config-operator_04_operator.* grant the verbs its controllers use. rbac_test.go syncs
+// every controller against fake clients that deny what these roles don't allow.
 package operator
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,7 +15,6 @@ import (
 	"github.com/blang/semver/v4"
 	"github.com/openshift/api/features"
 
-	"github.com/davecgh/go-spew/spew"
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configv1client "github.com/openshift/client-go/config/clientset/versioned"
@@ -22,16 +23,20 @@ import (
 	"github.com/openshift/cluster-config-operator/pkg/cmd/render"
 	"github.com/openshift/cluster-config-operator/pkg/operator/aws_platform_service_location"
 	"github.com/openshift/cluster-config-operator/pkg/operator/featuregates"
+	"github.com/openshift/cluster-config-operator/pkg/operator/featuresetmigration"
 	"github.com/openshift/cluster-config-operator/pkg/operator/featureupgradablecontroller"
+	"github.com/openshift/cluster-config-operator/pkg/operator/health"
+	"github.com/openshift/cluster-config-operator/pkg/operator/infrastructure_normalizer"
 	kubecloudconfig "github.com/openshift/cluster-config-operator/pkg/operator/kube_cloud_config"
-	"github.com/openshift/cluster-config-operator/pkg/operator/migrateokdfeatureset"
 	"github.com/openshift/cluster-config-operator/pkg/operator/migration_platform_status"
 	"github.com/openshift/cluster-config-operator/pkg/operator/operatorclient"
-	"github.com/openshift/cluster-config-operator/pkg/operator/removelatencysensitive"
+	"github.com/openshift/cluster-config-operator/pkg/operator/operatorstatus"
+	referencevalidation "github.com/openshift/cluster-config-operator/pkg/operator/reference_validation"
 	"github.com/openshift/cluster-config-operator/pkg/util"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	featuregatelib "github.com/openshift/library-go/pkg/operator/configobserver/featuregates"
+	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/genericoperatorclient"
 	"github.com/openshift/library-go/pkg/operator/loglevel"
 	"github.com/openshift/library-go/pkg/operator/staleconditions"
@@ -42,18 +47,39 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/server/healthz"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/clock"
 )
 
+// kubeInformerNamespaces are the namespaces the operator has informers for.
+var kubeInformerNamespaces = []string{
+	"",
+	operatorclient.GlobalUserSpecifiedConfigNamespace,
+	operatorclient.GlobalMachineSpecifiedConfigNamespace,
+	"kube-system",
+}
+
 type OperatorOptions struct {
 	OperatorVersion             string
 	AuthoritativeFeatureGateDir string
+
+	readiness *health.Readiness
 }
 
 func NewOperatorOptions() *OperatorOptions {
-	return &OperatorOptions{}
+	return &OperatorOptions{
+		readiness: health.NewReadiness(clock.RealClock{}),
+	}
+}
+
+// HealthChecks returns the checks backing the /readyz endpoint of the operator.
+func (o *OperatorOptions) HealthChecks() []healthz.HealthChecker {
+	return o.readiness.HealthChecks()
 }
 
 func (o *OperatorOptions) AddFlags(fs *pflag.FlagSet) {
@@ -78,12 +104,7 @@ func (o *OperatorOptions) RunOperator(ctx context.Context, controllerContext *co
 	}
 
 	configInformers := configv1informers.NewSharedInformerFactory(configClient, 10*time.Minute)
-	kubeInformersForNamespaces := v1helpers.NewKubeInformersForNamespaces(kubeClient,
-		"",
-		operatorclient.GlobalUserSpecifiedConfigNamespace,
-		operatorclient.GlobalMachineSpecifiedConfigNamespace,
-		"kube-system",
-	)
+	kubeInformersForNamespaces := v1helpers.NewKubeInformersForNamespaces(kubeClient, kubeInformerNamespaces...)
 	operatorClient, dynamicInformers, err := genericoperatorclient.NewClusterScopedOperatorClient(
 		clock.RealClock{},
 		controllerContext.KubeConfig,
@@ -106,12 +127,84 @@ func (o *OperatorOptions) RunOperator(ctx context.Context, controllerContext *co
 		configInformers.Config().V1().FeatureGates(),
 		controllerContext.EventRecorder,
 	)
+	o.readiness.WaitForInitialFeatureGates(featureGateAccessor.InitialFeatureGatesObserved())
+
+	versionRecorder, err := newVersionRecorder(ctx, configClient, o.OperatorVersion)
+	if err != nil {
+		return err
+	}
+
+	// The operator spec selects whether the kube-cloud-config Secrets are watched, it is read before the informers
+	// of the controllers are set up.
+	go dynamicInformers.Start(ctx.Done())
+	kubeCloudConfigSecrets, err := kubeCloudConfigSecretsRequired(ctx, operatorClient)
+	if err != nil {
+		return err
+	}
+
+	controllers := newControllers(o.OperatorVersion, featureGateDetails, operatorClient, kubeClient, configClient,
+		configInformers, kubeInformersForNamespaces, kubeCloudConfigSecrets, featureGateAccessor, versionRecorder,
+		o.readiness, controllerContext.Clock, controllerContext.EventRecorder)
+
+	// Start informers before waiting for feature gates - the feature gate accessor needs them running
+	go configInformers.Start(ctx.Done())
+	go kubeInformersForNamespaces.Start(ctx.Done())
+
+	// The featuregate controller must never be featuregated. It is responsible for ensuring the featuregate
+	// object status contains the correct feature gate states for the current release.  During upgrades, FGC will set
+	// the version field that the accessor will be checking; therefore, it must run before the featuregeate accessor
+	// as the accessor will fail to start if its version isn't updated.
+	go controllers.featureGate.Run(ctx, 1)
+
+	// Start the feature gate accessor, controllers that depend on feature gates (such as kube cloud config controller)
+	// are started once it has observed the initial feature gates.
+	go featureGateAccessor.Run(ctx)
+
+	klog.Info("Started feature gate accessor")
+
+	// Controllers that don't depend on feature gates start right away, so that a FeatureGate detection that
+	// doesn't complete doesn't stop the operator, in particular the featuregate controller that may resolve it.
+	for _, c := range controllers.ungated {
+		go c.Run(ctx, 1)
+	}
+
+	// Controllers that depend on feature gates wait for the feature gate accessor to observe initial feature gates.
+	go runWhenFeatureGatesObserved(ctx, featureGateAccessor.InitialFeatureGatesObserved(), 5*time.Minute, controllerContext.Clock,
+		operatorClient,
+		controllers.gated...,
+	)
 
+	<-ctx.Done()
+	return nil
+}
+
+// kubeCloudConfigSecretsRequired returns true if the unsupportedConfigOverrides of the operator select Secrets for the
+// kube-cloud-config, see kubecloudconfig.SecretsRequired. Overrides that cannot be parsed select none, the
+// KubeCloudConfigController reports them.
+func kubeCloudConfigSecretsRequired(ctx context.Context, operatorClient v1helpers.OperatorClient) (bool, error) {
+	if !cache.WaitForCacheSync(ctx.Done(), operatorClient.Informer().HasSynced) {
+		return false, fmt.Errorf("timed out waiting for the operator config to sync")
+	}
+	spec, _, _, err := operatorClient.GetOperatorState()
+	if err != nil {
+		return false, err
+	}
+	overrides, err := operatorclient.GetUnsupportedConfigOverrides(spec)
+	if err != nil {
+		klog.Warningf("Unable to select the kube-cloud-config kinds: %v", err)
+		return false, nil
+	}
+	return kubecloudconfig.SecretsRequired(overrides.KubeCloudConfig), nil
+}
+
+// newVersionRecorder returns a version recorder holding the versions of the config-operator ClusterOperator, so that
+// they don't change until the controllers sync, along with the operator version.
+func newVersionRecorder(ctx context.Context, configClient configv1client.Interface, operatorVersion string) (status.VersionGetter, error) {
 	// don't change any versions until we sync
 	versionRecorder := status.NewVersionGetter()
 	clusterOperator, err := configClient.ConfigV1().ClusterOperators().Get(ctx, "config-operator", metav1.GetOptions{})
 	if err != nil && !k8serrors.IsNotFound(err) {
-		return err
+		return nil, err
 	}
 	for _, version := range clusterOperator.Status.Versions {
 		versionRecorder.SetVersion(version.Name, version.Version)
@@ -120,58 +213,92 @@ func (o *OperatorOptions) RunOperator(ctx context.Context, controllerContext *co
 	if _, ok := versionRecorder.GetVersions()[featuregates.FeatureVersionName]; !ok {
 		versionRecorder.SetVersion(featuregates.FeatureVersionName, "")
 	}
-	versionRecorder.SetVersion("operator", o.OperatorVersion)
+	versionRecorder.SetVersion("operator", operatorVersion)
 
+	return versionRecorder, nil
+}
+
+// operatorControllers are the controllers run by the operator. The permissions they need are granted to the operator
+// in the RBAC manifests, see rbac_test.go.
+type operatorControllers struct {
+	// featureGate writes the FeatureGate status the feature gate accessor waits for, so it runs before it.
+	featureGate factory.Controller
+	// ungated controllers don't depend on feature gates and start right away.
+	ungated []factory.Controller
+	// gated controllers depend on feature gates and start once the initial feature gates have been observed.
+	gated []factory.Controller
+}
+
+// newControllers returns the controllers of the operator, using the given clients and informers. Every controller is
+// registered with readiness, see health.Readiness.Heartbeat.
+func newControllers(
+	operatorVersion string,
+	featureGateDetails map[configv1.FeatureSet]*features.FeatureGateEnabledDisabled,
+	operatorClient v1helpers.OperatorClient,
+	kubeClient kubernetes.Interface,
+	configClient configv1client.Interface,
+	configInformers configv1informers.SharedInformerFactory,
+	kubeInformersForNamespaces v1helpers.KubeInformersForNamespaces,
+	kubeCloudConfigSecrets bool,
+	featureGateAccessor featuregatelib.FeatureGateAccess,
+	versionRecorder status.VersionGetter,
+	readiness *health.Readiness,
+	clock clock.Clock,
+	recorder events.Recorder,
+) operatorControllers {
 	featureGateController := featuregates.NewFeatureGateController(
 		featureGateDetails,
-		operatorClient,
-		o.OperatorVersion,
+		readiness.Heartbeat("FeatureGateController", true, operatorClient),
+		operatorVersion,
 		configClient.ConfigV1(),
 		configInformers.Config().V1().FeatureGates(),
 		configInformers.Config().V1().ClusterVersions(),
 		versionRecorder,
-		controllerContext.EventRecorder,
+		recorder,
 	)
 
-	// to be removed a release after we block upgrades
-	latencySensitiveRemover := removelatencysensitive.NewLatencySensitiveRemovalController(
-		operatorClient,
-		configClient.ConfigV1(),
-		configInformers.Config().V1().FeatureGates(),
-		controllerContext.EventRecorder,
-	)
-
-	// Migrates Default featureset to OKD for OKD builds
-	// to be removed a release after OKD upgrades are stable
-	okdFeatureSetMigrator := migrateokdfeatureset.NewOKDFeatureSetMigrationController(
-		operatorClient,
+	// Rewrites deprecated featureSets, see featuresetmigration.DefaultRules
+	featureSetMigrationController := featuresetmigration.NewFeatureSetMigrationController(
+		readiness.Heartbeat("FeatureSetMigrationController", true, operatorClient),
 		configClient.ConfigV1(),
 		configInformers.Config().V1().FeatureGates(),
-		controllerContext.EventRecorder,
+		operatorVersion,
+		featuresetmigration.DefaultRules,
+		clock,
+		recorder,
 	)
 
 	featureUpgradeableController := featureupgradablecontroller.NewFeatureUpgradeableController(
-		operatorClient,
+		readiness.Heartbeat("FeatureUpgradeableController", false, operatorClient),
 		configInformers,
-		controllerContext.EventRecorder,
+		recorder,
 	)
 
 	infraController := aws_platform_service_location.NewController(
-		operatorClient,
+		readiness.Heartbeat("AWSPlatformServiceLocationController", true, operatorClient),
 		configClient.ConfigV1(),
 		configInformers.Config().V1().Infrastructures().Lister(),
 		configInformers.Config().V1().Infrastructures().Informer(),
-		controllerContext.EventRecorder,
+		recorder,
+	)
+
+	infrastructureNormalizerController := infrastructure_normalizer.NewController(
+		readiness.Heartbeat("InfrastructureNormalizerController", true, operatorClient),
+		configClient.ConfigV1(),
+		configInformers.Config().V1().Infrastructures().Lister(),
+		configInformers.Config().V1().Infrastructures().Informer(),
+		recorder,
 	)
 
 	migrationPlatformStatusController := migration_platform_status.NewController(
-		operatorClient,
+		readiness.Heartbeat("MigrationPlatformStatusController", true, operatorClient),
 		configClient.ConfigV1(),
 		configInformers.Config().V1().Infrastructures().Lister(),
 		configInformers.Config().V1().Infrastructures().Informer(),
 		v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
 		kubeInformersForNamespaces.InformersFor("kube-system").Core().V1().ConfigMaps().Informer(),
-		controllerContext.EventRecorder,
+		clock,
+		recorder,
 	)
 
 	statusController := status.NewClusterOperatorStatusController(
@@ -183,100 +310,97 @@ func (o *OperatorOptions) RunOperator(ctx context.Context, controllerContext *co
 		},
 		configClient.ConfigV1(),
 		configInformers.Config().V1().ClusterOperators(),
-		operatorClient,
+		readiness.Heartbeat("StatusSyncer_config-operator", true, operatorClient),
 		versionRecorder,
-		controllerContext.EventRecorder,
-		controllerContext.Clock,
+		recorder,
+		clock,
 	)
 
+	// the kube-cloud-config Secrets are only watched when they are selected
+	secretClient := corev1client.SecretsGetter(kubeClient.CoreV1())
+	var openshiftConfigSecretInformer, openshiftConfigManagedSecretInformer corev1informers.SecretInformer
+	if kubeCloudConfigSecrets {
+		secretClient = v1helpers.CachedSecretGetter(kubeClient.CoreV1(), kubeInformersForNamespaces)
+		openshiftConfigSecretInformer = kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets()
+		openshiftConfigManagedSecretInformer = kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().Secrets()
+	}
 	kubeCloudConfigController := kubecloudconfig.NewController(
-		operatorClient,
+		readiness.Heartbeat("KubeCloudConfigController", true, operatorClient),
 		configClient.ConfigV1(),
 		configInformers.Config().V1().Infrastructures().Lister(),
 		configInformers.Config().V1().Infrastructures().Informer(),
 		v1helpers.CachedConfigMapGetter(kubeClient.CoreV1(), kubeInformersForNamespaces),
-		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
-		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps().Informer(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
+		secretClient,
+		openshiftConfigSecretInformer,
+		openshiftConfigManagedSecretInformer,
 		featureGateAccessor,
-		controllerContext.EventRecorder,
+		clock,
+		recorder,
 	)
 
-	logLevelController := loglevel.NewClusterOperatorLoggingController(operatorClient, controllerContext.EventRecorder)
-
-	// As this operator does not manage any component/workload, report this operator as available and not progressing by default.
-	// TODO: Revisit this with full controller at some point.
-	operatorController := factory.New().ResyncEvery(10*time.Second).WithSync(func(ctx context.Context, controllerContext factory.SyncContext) error {
-		operatorStatus, updated, updateErr := v1helpers.UpdateStatus(ctx, operatorClient,
-			v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
-				Type:   "OperatorAvailable",
-				Status: operatorv1.ConditionTrue,
-				Reason: "AsExpected",
-			}),
-			v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
-				Type:   "OperatorProgressing",
-				Status: operatorv1.ConditionFalse,
-				Reason: "AsExpected",
-			}),
-			v1helpers.UpdateConditionFn(operatorv1.OperatorCondition{
-				Type:   "OperatorUpgradeable",
-				Status: operatorv1.ConditionTrue,
-				Reason: "AsExpected",
-			}),
-		)
-		if updated && operatorStatus != nil {
-			controllerContext.Recorder().Eventf("ConfigOperatorStatusChanged", "Operator conditions defaulted: %s", spew.Sprint(operatorStatus.Conditions))
-		}
-		return updateErr
-	}).ToController("ConfigOperatorController", controllerContext.EventRecorder)
+	logLevelController := loglevel.NewClusterOperatorLoggingController(readiness.Heartbeat("LoggingSyncer", false, operatorClient), recorder)
+
+	operatorController := operatorstatus.NewController(
+		readiness.Heartbeat("ConfigOperatorController", true, operatorClient),
+		operatorVersion,
+		configInformers.Config().V1().Infrastructures(),
+		configInformers.Config().V1().FeatureGates(),
+		configInformers.Config().V1().ClusterVersions(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalMachineSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
+		featureGateAccessor,
+		recorder,
+	)
+
+	referenceValidationController := referencevalidation.NewController(
+		readiness.Heartbeat("ReferenceValidationController", true, operatorClient),
+		configInformers.Config().V1().Proxies(),
+		configInformers.Config().V1().APIServers(),
+		configInformers.Config().V1().OAuths(),
+		configInformers.Config().V1().Images(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().ConfigMaps(),
+		kubeInformersForNamespaces.InformersFor(operatorclient.GlobalUserSpecifiedConfigNamespace).Core().V1().Secrets(),
+		recorder,
+	)
 
 	// The MigrationAWSStatus controller has been renamed to MigrationPlatformStatus. Consequently, the
 	// MigrationAWSStatusControllerDegraded conditions has been replaced with the
 	// MigrationPlatformStatusControllerDegraded condition. The old condition is stale and should be removed.
+	// Likewise the LatencySensitiveRemovalController and OKDFeatureSetMigrationController have been replaced
+	// by the FeatureSetMigrationController. OperatorUpgradeable was always True, the ClusterOperator defaults
+	// Upgradeable to True when no controller reports it.
 	staleConditionsController := staleconditions.NewRemoveStaleConditionsController(
 		"StaleConditionController",
-		[]string{"MigrationAWSStatusControllerDegraded"},
-		operatorClient,
-		controllerContext.EventRecorder,
+		[]string{
+			"MigrationAWSStatusControllerDegraded",
+			"LatencySensitiveRemovalControllerDegraded",
+			"OKDFeatureSetMigrationControllerDegraded",
+			"OperatorUpgradeable",
+		},
+		readiness.Heartbeat("StaleConditionController", true, operatorClient),
+		recorder,
 	)
 
-	// Start informers before waiting for feature gates - the feature gate accessor needs them running
-	go dynamicInformers.Start(ctx.Done())
-	go configInformers.Start(ctx.Done())
-	go kubeInformersForNamespaces.Start(ctx.Done())
-
-	// The featuregate controller must never be featuregated. It is responsible for ensuring the featuregate
-	// object status contains the correct feature gate states for the current release.  During upgrades, FGC will set
-	// the version field that the accessor will be checking; therefore, it must run before the featuregeate accessor
-	// as the accessor will fail to start if its version isn't updated.
-	go featureGateController.Run(ctx, 1)
-
-	// Start the feature gate accessor and wait for it to observe initial feature gates
-	// This must happen before creating controllers that depend on feature gates (such as kube cloud config controller)
-	go featureGateAccessor.Run(ctx)
-
-	klog.Info("Started feature gate accessor")
-	select {
-	case <-featureGateAccessor.InitialFeatureGatesObserved():
-		klog.Info("FeatureGates initialized")
-	case <-time.After(5 * time.Minute):
-		accessError := errors.New("timed out waiting for FeatureGate detection")
-		klog.Error(accessError, "unable to start operator")
-		return accessError
-	}
-
-	go infraController.Run(ctx, 1)
-	go kubeCloudConfigController.Run(ctx, 1)
-	go logLevelController.Run(ctx, 1)
-	go statusController.Run(ctx, 1)
-	go operatorController.Run(ctx, 1)
-	go migrationPlatformStatusController.Run(ctx, 1)
-	go staleConditionsController.Run(ctx, 1)
-	go latencySensitiveRemover.Run(ctx, 1)
-	go okdFeatureSetMigrator.Run(ctx, 1)
-	go featureUpgradeableController.Run(ctx, 1)
-
-	<-ctx.Done()
-	return nil
+	return operatorControllers{
+		featureGate: featureGateController,
+		ungated: []factory.Controller{
+			infraController,
+			infrastructureNormalizerController,
+			logLevelController,
+			statusController,
+			migrationPlatformStatusController,
+			staleConditionsController,
+			featureSetMigrationController,
+			featureUpgradeableController,
+			referenceValidationController,
+			operatorController,
+		},
+		gated: []factory.Controller{
+			kubeCloudConfigController,
+		},
+	}
 }
 
 func (o *OperatorOptions) getFeatureGateMappingFromDisk(ctx context.Context, configClient configv1client.Interface) (map[configv1.FeatureSet]*features.FeatureGateEnabledDisabled, error) {
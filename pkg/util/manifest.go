@@ -0,0 +1,133 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ReadManifestFile reads the file at path and decodes the single object of kind gvk into obj.
+// See ReadManifest for the accepted formats.
+func ReadManifestFile(path string, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := ReadManifest(data, gvk, obj); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadManifest decodes the single object of kind gvk from data into obj.
+// data can be a JSON document or a (multi-document) YAML stream, and any document may be a List
+// whose items are searched as well. It is an error if no object, or more than one object, matches gvk.
+// For compatibility with older inputs, a lone document without apiVersion and kind is decoded as-is.
+func ReadManifest(data []byte, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	docs, err := decodeManifests(data)
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 1 && docs[0].GroupVersionKind().Empty() {
+		return json.Unmarshal(docs[0].raw, obj)
+	}
+
+	var matches []manifest
+	for _, d := range docs {
+		if d.GroupVersionKind() == gvk {
+			matches = append(matches, d)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no %s found", gvkString(gvk))
+	case 1:
+		return json.Unmarshal(matches[0].raw, obj)
+	default:
+		names := make([]string, 0, len(matches))
+		for _, m := range matches {
+			names = append(names, m.Name)
+		}
+		return fmt.Errorf("expected exactly one %s, found %d: %q", gvkString(gvk), len(matches), names)
+	}
+}
+
+// manifest is a single decoded object along with its JSON encoding.
+type manifest struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Items             []json.RawMessage `json:"items,omitempty"`
+
+	raw []byte
+}
+
+// decodeManifests splits data into its documents and flattens any List into its items.
+func decodeManifests(data []byte) ([]manifest, error) {
+	var ret []manifest
+	decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for idx := 0; ; idx++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode document %d: %w", idx, err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			// empty document, e.g. a trailing "---"
+			continue
+		}
+
+		m, err := decodeManifest(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode document %d: %w", idx, err)
+		}
+		if !m.isList() {
+			ret = append(ret, m)
+			continue
+		}
+		for i, rawItem := range m.Items {
+			item, err := decodeManifest(rawItem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode item %d of list in document %d: %w", i, idx, err)
+			}
+			ret = append(ret, item)
+		}
+	}
+	return ret, nil
+}
+
+// isList returns true if m is a v1 List, or a typed list like an InfrastructureList. Other kinds ending in "List",
+// like custom resources, are only lists if they have items.
+func (m manifest) isList() bool {
+	if m.Kind == "List" {
+		return true
+	}
+	return strings.HasSuffix(m.Kind, "List") && m.Items != nil
+}
+
+func decodeManifest(raw []byte) (manifest, error) {
+	m := manifest{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return manifest{}, err
+	}
+	m.raw = raw
+	return m, nil
+}
+
+func gvkString(gvk schema.GroupVersionKind) string {
+	if len(gvk.Group) == 0 {
+		return fmt.Sprintf("%s.%s", gvk.Kind, gvk.Version)
+	}
+	return fmt.Sprintf("%s.%s.%s", gvk.Kind, gvk.Version, gvk.Group)
+}
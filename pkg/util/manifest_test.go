@@ -0,0 +1,165 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestReadManifest(t *testing.T) {
+	infraGVK := configv1.GroupVersion.WithKind("Infrastructure")
+
+	cases := []struct {
+		name     string
+		input    string
+		platform configv1.PlatformType
+		err      string
+	}{{
+		name: "single yaml document",
+		input: `apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+status:
+  platform: AWS
+`,
+		platform: configv1.AWSPlatformType,
+	}, {
+		name:     "single json document",
+		input:    `{"apiVersion":"config.openshift.io/v1","kind":"Infrastructure","metadata":{"name":"cluster"},"status":{"platform":"Azure"}}`,
+		platform: configv1.AzurePlatformType,
+	}, {
+		name: "document without type information",
+		input: `metadata:
+  name: cluster
+status:
+  platform: GCP
+`,
+		platform: configv1.GCPPlatformType,
+	}, {
+		name: "multi document yaml",
+		input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cloud-provider-config
+  namespace: openshift-config
+---
+apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+status:
+  platform: VSphere
+---
+`,
+		platform: configv1.VSpherePlatformType,
+	}, {
+		name: "list",
+		input: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: cloud-provider-config
+    namespace: openshift-config
+- apiVersion: config.openshift.io/v1
+  kind: Infrastructure
+  metadata:
+    name: cluster
+  status:
+    platform: OpenStack
+`,
+		platform: configv1.OpenStackPlatformType,
+	}, {
+		name: "typed list",
+		input: `apiVersion: config.openshift.io/v1
+kind: InfrastructureList
+items:
+- apiVersion: config.openshift.io/v1
+  kind: Infrastructure
+  metadata:
+    name: cluster
+  status:
+    platform: Nutanix
+`,
+		platform: configv1.NutanixPlatformType,
+	}, {
+		name: "missing object",
+		input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cloud-provider-config
+`,
+		err: `^no Infrastructure\.v1\.config\.openshift\.io found$`,
+	}, {
+		name: "duplicate objects",
+		input: `apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+---
+apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: other
+`,
+		err: `^expected exactly one Infrastructure\.v1\.config\.openshift\.io, found 2: \["cluster" "other"\]$`,
+	}, {
+		name:  "malformed document",
+		input: `{"apiVersion": `,
+		err:   `^failed to decode document 0: `,
+	}}
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{}
+			err := ReadManifest([]byte(test.input), infraGVK, infra)
+			if test.err != "" {
+				assert.Regexp(t, test.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.platform, infra.Status.Platform)
+		})
+	}
+}
+
+func TestReadManifest_ConfigMap(t *testing.T) {
+	input := `apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cloud-provider-config
+  namespace: openshift-config
+data:
+  config: |
+    [Global]
+`
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, ReadManifest([]byte(input), corev1.SchemeGroupVersion.WithKind("ConfigMap"), cm))
+	assert.Equal(t, "cloud-provider-config", cm.Name)
+	assert.Equal(t, map[string]string{"config": "[Global]\n"}, cm.Data)
+}
+
+func TestReadManifest_KindEndingInList(t *testing.T) {
+	input := `apiVersion: example.com/v1
+kind: AccessList
+metadata:
+  name: allowed
+spec:
+  users:
+  - admin
+`
+	obj := &unstructured.Unstructured{}
+	assert.NoError(t, ReadManifest([]byte(input), schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "AccessList"}, obj))
+	assert.Equal(t, "allowed", obj.GetName())
+}
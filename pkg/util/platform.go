@@ -0,0 +1,15 @@
+package util
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// PlatformType returns the platform type of infra from status.platformStatus.type, falling back to the deprecated
+// status.platform. The InfrastructureNormalizerController keeps both fields consistent, so the fallback only
+// matters until it has synced.
+func PlatformType(infra *configv1.Infrastructure) configv1.PlatformType {
+	if pstatus := infra.Status.PlatformStatus; pstatus != nil && len(pstatus.Type) > 0 {
+		return pstatus.Type
+	}
+	return infra.Status.Platform
+}